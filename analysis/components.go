@@ -0,0 +1,127 @@
+// Package analysis measures and segments images.
+//
+// The package works with the Image interface described in the image package.
+//
+// Most functions work on grayscale images, where nonzero pixels are foreground,
+// such as the masks produced by thresholding.
+package analysis
+
+import (
+	"image"
+)
+
+// ConnectedComponents labels the connected foreground (nonzero) pixels of a grayscale image.
+//
+// Connectivity must be either 4 or 8.
+// Labels are returned in row-major order, one per pixel of img.Bounds().
+// Background pixels are labeled 0, components are labeled 1 to count.
+func ConnectedComponents(img *image.Gray, connectivity int) (labels []int32, count int) {
+	if connectivity != 4 && connectivity != 8 {
+		panic("Unknown connectivity")
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	labels = make([]int32, w*h)
+	parent := []int32{0}
+
+	find := func(l int32) int32 {
+		for parent[l] != l {
+			parent[l] = parent[parent[l]]
+			l = parent[l]
+		}
+		return l
+	}
+	union := func(a, b int32) int32 {
+		a, b = find(a), find(b)
+		if a > b {
+			a, b = b, a
+		}
+		if b != 0 {
+			parent[b] = a
+		}
+		return a
+	}
+
+	// first pass: provisional labels, record equivalences
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			if row[x] == 0 {
+				continue
+			}
+
+			var l int32
+			merge := func(n int32) {
+				switch {
+				case n == 0:
+				case l == 0:
+					l = n
+				default:
+					l = union(l, n)
+				}
+			}
+
+			i := y*w + x
+			if x > 0 {
+				merge(labels[i-1])
+			}
+			if y > 0 {
+				merge(labels[i-w])
+				if connectivity == 8 {
+					if x > 0 {
+						merge(labels[i-w-1])
+					}
+					if x < w-1 {
+						merge(labels[i-w+1])
+					}
+				}
+			}
+
+			if l == 0 {
+				l = int32(len(parent))
+				parent = append(parent, l)
+			}
+			labels[i] = l
+		}
+	}
+
+	// second pass: resolve equivalences, number components consecutively
+	final := make([]int32, len(parent))
+	for l := range parent[1:] {
+		l := int32(l + 1)
+		if r := find(l); r == l {
+			count++
+			final[l] = int32(count)
+		} else {
+			final[l] = final[r]
+		}
+	}
+	for i, l := range labels {
+		labels[i] = final[l]
+	}
+
+	return labels, count
+}
+
+// ComponentBounds returns the bounding box of each labeled component,
+// given the labels and count returned by ConnectedComponents, and the width (stride) of the labeled image.
+//
+// The bounding box of the component labeled l is at index l-1.
+// Boxes are relative to the minimum point of the labeled image.
+// The background (label 0) is ignored.
+func ComponentBounds(labels []int32, stride, count int) []image.Rectangle {
+	res := make([]image.Rectangle, count)
+	for i, l := range labels {
+		if l == 0 {
+			continue
+		}
+		x, y := i%stride, i/stride
+		p := image.Rect(x, y, x+1, y+1)
+		if r := &res[l-1]; r.Empty() {
+			*r = p
+		} else {
+			*r = r.Union(p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_ConnectedComponents(t *testing.T) {
+	img := image.NewGray(image.Rect(5, 5, 25, 25))
+	set := func(x0, y0, x1, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.Pix[img.PixOffset(x+5, y+5)] = 255
+			}
+		}
+	}
+
+	set(0, 0, 3, 3)     // square
+	set(5, 0, 6, 10)    // U shape, merged late
+	set(9, 0, 10, 10)   //
+	set(5, 9, 10, 10)   //
+	set(12, 12, 13, 13) // diagonal pair
+	set(13, 13, 14, 14) //
+	set(0, 19, 20, 20)  // bottom line
+
+	labels, count := ConnectedComponents(img, 4)
+	if count != 5 {
+		t.Errorf("4-connectivity, expected: 5, got: %d", count)
+	}
+	if len(labels) != 400 {
+		t.Fatalf("unexpected labels length: %d", len(labels))
+	}
+	if labels[0] == 0 || labels[0] != labels[2*20+2] {
+		t.Error("square not labeled")
+	}
+	if labels[5] == 0 || labels[5] != labels[9] || labels[5] == labels[0] {
+		t.Error("U shape not merged")
+	}
+	if labels[12*20+12] == labels[13*20+13] {
+		t.Error("diagonal pair merged")
+	}
+	if labels[3*20] != 0 || labels[4] != 0 {
+		t.Error("background labeled")
+	}
+
+	labels, count = ConnectedComponents(img, 8)
+	if count != 4 {
+		t.Errorf("8-connectivity, expected: 4, got: %d", count)
+	}
+	if labels[12*20+12] != labels[13*20+13] {
+		t.Error("diagonal pair not merged")
+	}
+
+	seen := map[int32]bool{}
+	for _, l := range labels {
+		if int(l) > count {
+			t.Fatalf("label out of range: %d", l)
+		}
+		seen[l] = true
+	}
+	if len(seen) != count+1 {
+		t.Errorf("labels not consecutive: %v", seen)
+	}
+}
+
+func Test_ComponentBounds(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 10))
+	set := func(x0, y0, x1, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.Pix[img.PixOffset(x, y)] = 255
+			}
+		}
+	}
+
+	set(1, 2, 4, 5)   // 3x3 square
+	set(10, 3, 15, 8) // 5x5 square
+
+	labels, count := ConnectedComponents(img, 4)
+	bounds := ComponentBounds(labels, 20, count)
+	if len(bounds) != 2 {
+		t.Fatalf("expected: 2, got: %d", len(bounds))
+	}
+	if r := image.Rect(1, 2, 4, 5); bounds[0] != r {
+		t.Errorf("expected: %v, got: %v", r, bounds[0])
+	}
+	if r := image.Rect(10, 3, 15, 8); bounds[1] != r {
+		t.Errorf("expected: %v, got: %v", r, bounds[1])
+	}
+}
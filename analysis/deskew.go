@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Deskew straightens a skewed image, such as a document scan.
+//
+// The skew angle is estimated from the image's edges, within ±15 degrees,
+// as the rotation that best aligns them horizontally and vertically.
+// The image is then rotated about its center to correct it.
+// The corrected image has the same size as the original, with the exposed corners transparent.
+//
+// Deskew returns the corrected image, and the angle by which it was rotated,
+// in degrees, positive counterclockwise.
+func Deskew(img image.Image) (image.Image, float64) {
+	angle := -skewAngle(edgeMap(toGray(img)), 15, true)
+	return rotate(img, angle), angle
+}
+
+// skewAngle estimates the angle (in degrees, positive counterclockwise)
+// by which the dominant near-horizontal (and, optionally, near-vertical) edges of an image are rotated.
+//
+// Each candidate angle is scored by projecting the edge pixels perpendicularly to the rotated axes:
+// the sharper the projection profiles, the better aligned the edges.
+// Candidates are searched coarse to fine, within ±maxDegrees.
+func skewAngle(edges *image.Gray, maxDegrees float64, vertical bool) float64 {
+	w, h := edges.Rect.Dx(), edges.Rect.Dy()
+
+	var pts []image.Point
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if edges.Pix[y*edges.Stride+x] != 0 {
+				pts = append(pts, image.Pt(x, y))
+			}
+		}
+	}
+
+	if len(pts) == 0 {
+		return 0
+	}
+
+	offset := w + h
+	hist := make([]int32, 2*offset+1)
+
+	score := func(degrees float64) float64 {
+		sin, cos := math.Sincos(degrees * math.Pi / 180)
+
+		project := func(f func(p image.Point) float64) (s float64) {
+			clear(hist)
+			for _, p := range pts {
+				hist[int(math.Round(f(p)))+offset]++
+			}
+			for _, n := range hist {
+				s += float64(n) * float64(n)
+			}
+			return s
+		}
+
+		// distance along the normal of rotated horizontal lines
+		s := project(func(p image.Point) float64 { return float64(p.X)*sin + float64(p.Y)*cos })
+		if vertical {
+			// distance along the normal of rotated vertical lines
+			s += project(func(p image.Point) float64 { return float64(p.X)*cos - float64(p.Y)*sin })
+		}
+		return s
+	}
+
+	// ties keep best, so an image without a dominant direction isn't rotated
+	search := func(lo, hi, step, best float64) float64 {
+		max := score(best)
+		for a := lo; a <= hi+step/2; a += step {
+			if s := score(a); s > max {
+				max, best = s, a
+			}
+		}
+		return best
+	}
+
+	best := search(-maxDegrees, maxDegrees, 0.5, 0)
+	lo := math.Max(best-0.5, -maxDegrees)
+	hi := math.Min(best+0.5, +maxDegrees)
+	return search(lo, hi, 0.05, best)
+}
+
+// edgeMap thresholds the Sobel gradient magnitude of a grayscale image.
+// Pixels with more than a quarter of the maximum magnitude become 255, other pixels become 0.
+func edgeMap(img *image.Gray) *image.Gray {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+
+	at := func(x, y int) int {
+		x = max(0, min(x, w-1))
+		y = max(0, min(y, h-1))
+		return int(img.Pix[y*img.Stride+x])
+	}
+
+	mag := make([]int, w*h)
+	var maxMag int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			m := abs(gx) + abs(gy)
+			mag[y*w+x] = m
+			maxMag = max(maxMag, m)
+		}
+	}
+
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	for i, m := range mag {
+		if maxMag > 0 && 4*m > maxMag {
+			dst.Pix[i] = 255
+		}
+	}
+	return dst
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// LevelHorizon straightens a photo with a tilted horizon.
+//
+// The tilt is estimated from the image's dominant near-horizontal edges, within ±maxDegrees.
+// Unlike Deskew, vertical edges are ignored, as they're often not vertical in photos (e.g. due to perspective).
+// The image is then rotated about its center to level it,
+// and cropped to the largest centered rectangle with the same aspect ratio that excludes the exposed corners.
+// The result is anchored at the origin; if the horizon is already level, img is returned as is.
+//
+// LevelHorizon returns the corrected image, and the angle by which it was rotated,
+// in degrees, positive counterclockwise.
+func LevelHorizon(img image.Image, maxDegrees float64) (image.Image, float64) {
+	if !(maxDegrees > 0) {
+		return img, 0
+	}
+	angle := -skewAngle(edgeMap(toGray(img)), maxDegrees, false)
+	if angle == 0 {
+		return img, 0
+	}
+	rot := rotate(img, angle)
+
+	// scale the image down until the rotated crop fits inside it
+	w, h := float64(rot.Rect.Dx()), float64(rot.Rect.Dy())
+	sin, cos := math.Sincos(math.Abs(angle) * math.Pi / 180)
+	s := math.Min(w/(w*cos+h*sin), h/(w*sin+h*cos))
+
+	// leave out a pixel on each side, blended with the exposed corners
+	cw, ch := max(0, int(s*w)-2), max(0, int(s*h)-2)
+	x, y := (rot.Rect.Dx()-cw)/2, (rot.Rect.Dy()-ch)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, cw, ch))
+	draw.Draw(dst, dst.Rect, rot, image.Pt(x, y), draw.Src)
+	return dst, angle
+}
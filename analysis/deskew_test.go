@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_Deskew(t *testing.T) {
+	for _, skew := range []float64{-12, -3.5, 0, 7} {
+		// a checkerboard, rotated counterclockwise by skew degrees
+		img := image.NewGray(image.Rect(0, 0, 200, 160))
+		sin, cos := math.Sincos(skew * math.Pi / 180)
+		for y := 0; y < 160; y++ {
+			for x := 0; x < 200; x++ {
+				dx, dy := float64(x)+0.5-100, float64(y)+0.5-80
+				u := dx*cos - dy*sin
+				v := dx*sin + dy*cos
+				if (int(math.Floor(u/20))+int(math.Floor(v/20)))%2 == 0 {
+					img.Pix[y*img.Stride+x] = 255
+				}
+			}
+		}
+
+		res, angle := Deskew(img)
+		if math.Abs(angle+skew) > 0.25 {
+			t.Errorf("%v°: expected: %v, got: %v", skew, -skew, angle)
+		}
+		if b := res.Bounds(); b != img.Rect {
+			t.Errorf("%v°: unexpected bounds: %v", skew, b)
+		}
+
+		// residual skew
+		if _, angle := Deskew(res); math.Abs(angle) > 0.25 {
+			t.Errorf("%v°: residual skew: %v", skew, angle)
+		}
+	}
+}
+
+func Test_rotate(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(10 * i)
+	}
+
+	res := rotate(img, 180)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			c := res.RGBAAt(x, y)
+			e := img.GrayAt(3-x, 1-y).Y
+			if c.R != e || c.A != 255 {
+				t.Errorf("at %dx%d, expected: %v, got: %v", x, y, e, c)
+			}
+		}
+	}
+}
+
+func Test_LevelHorizon(t *testing.T) {
+	for _, tilt := range []float64{-6, 2.5, 0} {
+		// a bright sky over dark ground, with a horizon tilted counterclockwise by tilt degrees,
+		// and a slanted pole
+		img := image.NewRGBA(image.Rect(0, 0, 240, 160))
+		tan := math.Tan(tilt * math.Pi / 180)
+		for y := 0; y < 160; y++ {
+			for x := 0; x < 240; x++ {
+				c := color.RGBA{40, 60, 30, 255}
+				if float64(y)+0.5 < 90-(float64(x)+0.5-120)*tan {
+					c = color.RGBA{160, 200, 250, 255}
+				}
+				if d := float64(x) - 60 - 0.3*float64(y); 0 <= d && d < 4 {
+					c = color.RGBA{90, 70, 50, 255}
+				}
+				img.SetRGBA(x, y, c)
+			}
+		}
+
+		res, angle := LevelHorizon(img, 10)
+		if math.Abs(angle+tilt) > 0.25 {
+			t.Errorf("%v°: expected: %v, got: %v", tilt, -tilt, angle)
+		}
+
+		b := res.Bounds()
+		if b.Min != (image.Point{}) || b.Dx() > 240 || b.Dy() > 160 || math.Abs(float64(b.Dx())/float64(b.Dy())-1.5) > 0.05 {
+			t.Errorf("%v°: unexpected bounds: %v", tilt, b)
+		}
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				if _, _, _, a := res.At(x, y).RGBA(); a != 0xffff {
+					t.Fatalf("%v°: exposed corner at %dx%d", tilt, x, y)
+				}
+			}
+		}
+
+		// residual tilt
+		if _, angle := LevelHorizon(res, 10); math.Abs(angle) > 0.25 {
+			t.Errorf("%v°: residual tilt: %v", tilt, angle)
+		}
+	}
+
+	// a flat image, without edges, is not rotated
+	flat := image.NewGray(image.Rect(0, 0, 100, 80))
+	for i := range flat.Pix {
+		flat.Pix[i] = 128
+	}
+	if res, angle := LevelHorizon(flat, 10); angle != 0 || res != image.Image(flat) {
+		t.Errorf("flat: expected the image as is, got %v rotated by %v", res.Bounds(), angle)
+	}
+	if _, angle := Deskew(flat); angle != 0 {
+		t.Errorf("flat: expected: 0, got: %v", angle)
+	}
+}
@@ -0,0 +1,127 @@
+package analysis
+
+import (
+	"image"
+	"math"
+)
+
+// DistanceTransform computes, for each foreground (nonzero) pixel of a grayscale image,
+// the Euclidean distance to the nearest background (zero) pixel.
+//
+// Distances are in pixels, rounded to the nearest integer.
+// Background pixels have distance 0.
+// Pixels outside the image are not considered background:
+// if there are no background pixels, every distance saturates at 65535.
+func DistanceTransform(img *image.Gray) *image.Gray16 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dst := image.NewGray16(image.Rect(0, 0, w, h))
+
+	sqr := squaredDistances(img)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := math.Floor(math.Sqrt(sqr[y*w+x]) + 0.5)
+			if d > 65535 {
+				d = 65535
+			}
+			i := y*dst.Stride + 2*x
+			dst.Pix[i+0] = uint8(uint16(d) >> 8)
+			dst.Pix[i+1] = uint8(uint16(d))
+		}
+	}
+
+	return dst
+}
+
+// squaredDistances computes the exact squared Euclidean distance transform,
+// in row-major order, using the separable algorithm by Felzenszwalb and Huttenlocher.
+func squaredDistances(img *image.Gray) []float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	res := make([]float64, w*h)
+
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			if row[x] != 0 {
+				res[y*w+x] = math.Inf(1)
+			}
+		}
+	}
+
+	n := w
+	if n < h {
+		n = h
+	}
+	f := make([]float64, n)
+	d := make([]float64, n)
+	v := make([]int, n)
+	z := make([]float64, n+1)
+
+	// columns
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			f[y] = res[y*w+x]
+		}
+		edt1d(d[:h], f[:h], v, z)
+		for y := 0; y < h; y++ {
+			res[y*w+x] = d[y]
+		}
+	}
+
+	// rows
+	for y := 0; y < h; y++ {
+		copy(f, res[y*w:y*w+w])
+		edt1d(res[y*w:y*w+w], f[:w], v, z)
+	}
+
+	return res
+}
+
+// edt1d computes the 1D squared distance transform of f into d,
+// using v and z as scratch space.
+func edt1d(d, f []float64, v []int, z []float64) {
+	// lower envelope of the parabolas rooted at finite samples
+	k := -1
+	for q := range f {
+		if math.IsInf(f[q], 1) {
+			continue
+		}
+		if k < 0 {
+			k = 0
+			v[0] = q
+			z[0] = math.Inf(-1)
+			z[1] = math.Inf(+1)
+			continue
+		}
+
+		var s float64
+		for {
+			r := v[k]
+			s = ((f[q] + float64(q*q)) - (f[r] + float64(r*r))) / float64(2*(q-r))
+			if s > z[k] {
+				break
+			}
+			k--
+		}
+		k++
+		v[k] = q
+		z[k] = s
+		z[k+1] = math.Inf(+1)
+	}
+
+	if k < 0 {
+		for q := range d {
+			d[q] = math.Inf(+1)
+		}
+		return
+	}
+
+	k = 0
+	for q := range d {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		r := v[k]
+		d[q] = float64((q-r)*(q-r)) + f[r]
+	}
+}
@@ -0,0 +1,85 @@
+package analysis
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_DistanceTransform(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 9, 9))
+	img.Pix[img.PixOffset(4, 4)] = 255
+
+	dst := DistanceTransform(img)
+	if dst.Rect != img.Rect {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for i := range img.Pix {
+		exp := uint16(0)
+		if i == 4*9+4 {
+			exp = 1
+		}
+		if res := dst.Gray16At(i%9, i/9).Y; res != exp {
+			t.Errorf("isolated at %d, expected: %d, got: %d", i, exp, res)
+		}
+	}
+
+	// a square of foreground has rings of increasing distance
+	img = image.NewGray(image.Rect(0, 0, 11, 11))
+	for y := 1; y < 10; y++ {
+		for x := 1; x < 10; x++ {
+			img.Pix[img.PixOffset(x, y)] = 255
+		}
+	}
+	dst = DistanceTransform(img)
+	for y := 0; y < 11; y++ {
+		for x := 0; x < 11; x++ {
+			exp := x
+			for _, d := range []int{y, 10 - x, 10 - y} {
+				if d < exp {
+					exp = d
+				}
+			}
+			if res := dst.Gray16At(x, y).Y; int(res) != exp {
+				t.Errorf("ring at %dx%d, expected: %d, got: %d", x, y, exp, res)
+			}
+		}
+	}
+
+	// everything foreground saturates
+	for i := range img.Pix {
+		img.Pix[i] = 1
+	}
+	dst = DistanceTransform(img)
+	if res := dst.Gray16At(5, 5).Y; res != 65535 {
+		t.Errorf("no background, got: %d", res)
+	}
+}
+
+func Test_SquaredDistances(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 23, 17))
+	rnd := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		if rnd.Intn(10) != 0 {
+			img.Pix[i] = 255
+		}
+	}
+
+	sqr := squaredDistances(img)
+	for y := 0; y < 17; y++ {
+		for x := 0; x < 23; x++ {
+			exp := math.Inf(1)
+			for v := 0; v < 17; v++ {
+				for u := 0; u < 23; u++ {
+					if img.Pix[img.PixOffset(u, v)] == 0 {
+						exp = math.Min(exp, float64((x-u)*(x-u)+(y-v)*(y-v)))
+					}
+				}
+			}
+			if res := sqr[y*23+x]; res != exp {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, exp, res)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"image"
+)
+
+// focusRadius is the radius of the window over which FocusMap measures sharpness.
+const focusRadius = 4
+
+// FocusMap measures how in focus each region of an image is,
+// as the local variance of the Laplacian over a 9×9 window
+// (the neighborhood shrinks at the edges of the image).
+//
+// Values are scaled so the sharpest region is 255, making the result usable as a weight map
+// for compose.CompositeByWeight (e.g. for focus stacking).
+// The result is anchored at the origin.
+func FocusMap(img *image.Gray) *image.Gray {
+	src := toGray(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 {
+		return dst
+	}
+
+	// 4-neighbor Laplacian, extending edge pixels
+	at := func(x, y int) int64 {
+		return int64(src.Pix[max(0, min(y, h-1))*src.Stride+max(0, min(x, w-1))])
+	}
+	lap := make([]int64, w*h)
+	sqr := make([]int64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			l := at(x-1, y) + at(x+1, y) + at(x, y-1) + at(x, y+1) - 4*at(x, y)
+			lap[y*w+x] = l
+			sqr[y*w+x] = l * l
+		}
+	}
+	sum := integralSlice(lap, w, h)
+	sum2 := integralSlice(sqr, w, h)
+
+	variance := make([]float64, w*h)
+	var peak float64
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-focusRadius, 0), min(y+focusRadius+1, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(x-focusRadius, 0), min(x+focusRadius+1, w)
+
+			n := float64((x1 - x0) * (y1 - y0))
+			s := float64(sum.rect(x0, y0, x1, y1))
+			s2 := float64(sum2.rect(x0, y0, x1, y1))
+			v := max(0, s2/n-(s/n)*(s/n))
+			variance[y*w+x] = v
+			peak = max(peak, v)
+		}
+	}
+
+	if peak > 0 {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				dst.Pix[y*dst.Stride+x] = uint8(255*variance[y*w+x]/peak + 0.5)
+			}
+		}
+	}
+	return dst
+}
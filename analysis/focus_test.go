@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func Test_FocusMap(t *testing.T) {
+	// random texture, box blurred on the right half
+	rnd := rand.New(rand.NewSource(5))
+	sharp := image.NewGray(image.Rect(0, 0, 64, 32))
+	rnd.Read(sharp.Pix)
+
+	img := image.NewGray(image.Rect(2, 2, 66, 34))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			v := int(sharp.Pix[y*sharp.Stride+x])
+			if x >= 32 {
+				var s, n int
+				for dy := -2; dy <= 2; dy++ {
+					for dx := -2; dx <= 2; dx++ {
+						if p := image.Pt(x+dx, y+dy); p.In(sharp.Rect) {
+							s += int(sharp.GrayAt(p.X, p.Y).Y)
+							n++
+						}
+					}
+				}
+				v = s / n
+			}
+			img.Pix[y*img.Stride+x] = uint8(v)
+		}
+	}
+
+	focus := FocusMap(img)
+	if r := image.Rect(0, 0, 64, 32); focus.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, focus.Rect)
+	}
+
+	mean := func(x0, x1 int) float64 {
+		var s int
+		for y := 0; y < 32; y++ {
+			for x := x0; x < x1; x++ {
+				s += int(focus.Pix[y*focus.Stride+x])
+			}
+		}
+		return float64(s) / float64(32*(x1-x0))
+	}
+	if s, b := mean(0, 24), mean(40, 64); s < 4*b {
+		t.Errorf("expected the sharp region to score higher: %f, %f", s, b)
+	}
+
+	var peak uint8
+	for _, v := range focus.Pix {
+		peak = max(peak, v)
+	}
+	if peak != 255 {
+		t.Errorf("expected a peak of 255, got %d", peak)
+	}
+
+	// a flat image has no focus
+	flat := FocusMap(image.NewGray(image.Rect(0, 0, 8, 8)))
+	for _, v := range flat.Pix {
+		if v != 0 {
+			t.Fatalf("expected 0, got %d", v)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package analysis
+
+import (
+	"image"
+	"math/bits"
+)
+
+// Hash computes a 64-bit perceptual hash of an image:
+// similar images have hashes within a small Hamming distance.
+type Hash func(img image.Image) uint64
+
+// AverageHash computes a perceptual hash by shrinking the grayscale image to 8×8 (averaging areas),
+// and setting a bit for each of those pixels that is at least their mean.
+func AverageHash(img image.Image) uint64 {
+	cells := shrinkGray(toGray(img), 8, 8)
+	if cells == nil {
+		return 0
+	}
+
+	var sum int64
+	for _, v := range cells {
+		sum += v
+	}
+
+	var hash uint64
+	for i, v := range cells {
+		if 64*v >= sum {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// DifferenceHash computes a perceptual hash by shrinking the grayscale image to 9×8 (averaging areas),
+// and setting a bit for each pair of horizontally adjacent pixels where the left one is darker.
+// It is more robust than AverageHash to overall changes in brightness and contrast.
+func DifferenceHash(img image.Image) uint64 {
+	cells := shrinkGray(toGray(img), 9, 8)
+	if cells == nil {
+		return 0
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if cells[9*y+x] < cells[9*y+x+1] {
+				hash |= 1 << (8*y + x)
+			}
+		}
+	}
+	return hash
+}
+
+// HammingDistance counts the bits that differ between two hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// DedupFrames finds near-duplicates in a sequence of frames (e.g. burst photos, or video frames),
+// using DifferenceHash.
+// See DedupFramesHash.
+func DedupFrames(imgs []image.Image, threshold int) []int {
+	return DedupFramesHash(imgs, threshold, DifferenceHash)
+}
+
+// DedupFramesHash finds near-duplicates in a sequence of frames, using the given perceptual hash.
+//
+// Frames are considered in order: a frame is a duplicate if its hash is within threshold
+// Hamming distance of the hash of a kept frame; otherwise, it is kept.
+// The indices of the duplicates are returned, in increasing order.
+func DedupFramesHash(imgs []image.Image, threshold int, hash Hash) []int {
+	var dups []int
+	var kept []uint64
+	for i, img := range imgs {
+		h := hash(img)
+		dup := false
+		for _, k := range kept {
+			if HammingDistance(h, k) <= threshold {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			dups = append(dups, i)
+		} else {
+			kept = append(kept, h)
+		}
+	}
+	return dups
+}
+
+// shrinkGray sums the pixels of a grayscale image over a w×h grid of (nearly) equal areas,
+// in row-major order, each weighted to the average size of the areas.
+// Returns nil for an empty image.
+func shrinkGray(img *image.Gray, w, h int) []int64 {
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	if sw <= 0 || sh <= 0 {
+		return nil
+	}
+
+	sum := integral(img)
+	res := make([]int64, w*h)
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+			area := int64((x1 - x0) * (y1 - y0))
+			res[y*w+x] = sum.rect(x0, y0, x1, y1) * int64(sw*sh) / area
+		}
+	}
+	return res
+}
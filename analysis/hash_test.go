@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"image"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func Test_DedupFrames(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	frame := func(f func(x, y int) int, noise int) image.Image {
+		img := image.NewGray(image.Rect(0, 0, 64, 48))
+		for y := 0; y < 48; y++ {
+			for x := 0; x < 64; x++ {
+				v := f(x, y)
+				if noise > 0 {
+					v += rnd.Intn(2*noise+1) - noise
+				}
+				img.Pix[y*img.Stride+x] = uint8(max(0, min(v, 255)))
+			}
+		}
+		return img
+	}
+
+	blob := func(cx, cy int) func(x, y int) int {
+		return func(x, y int) int {
+			return 255 - 3*((x-cx)*(x-cx)+(y-cy)*(y-cy))/8
+		}
+	}
+	frames := []image.Image{
+		frame(blob(16, 16), 0),
+		frame(blob(48, 32), 0),
+		frame(blob(48, 32), 4), // near-duplicate
+		frame(func(x, y int) int { return 4 * x }, 0),
+		frame(func(x, y int) int { return 5 * y }, 0),
+	}
+
+	for _, hash := range []Hash{AverageHash, DifferenceHash} {
+		if got := DedupFramesHash(frames, 5, hash); !reflect.DeepEqual(got, []int{2}) {
+			t.Errorf("expected: [2], got: %v", got)
+		}
+	}
+	if got := DedupFrames(frames, 5); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("expected: [2], got: %v", got)
+	}
+	if got := DedupFrames(frames, 64); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("expected: [1 2 3 4], got: %v", got)
+	}
+}
+
+func Test_HammingDistance(t *testing.T) {
+	if d := HammingDistance(0xf0, 0x0f); d != 8 {
+		t.Errorf("expected: 8, got: %d", d)
+	}
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	if h := AverageHash(img); h != ^uint64(0) {
+		t.Errorf("expected all bits set, got: %x", h)
+	}
+	if h := DifferenceHash(image.NewGray(image.Rect(0, 0, 0, 0))); h != 0 {
+		t.Errorf("expected: 0, got: %x", h)
+	}
+}
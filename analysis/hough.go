@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Line is a straight line in Hesse normal form: x·cos(Theta) + y·sin(Theta) = Rho.
+//
+// Coordinates are relative to the minimum point of the image,
+// and Theta is in radians, in [0, π[.
+type Line struct {
+	Rho, Theta float64
+	Votes      int
+}
+
+// houghThetas is the number of angle bins: a resolution of ¼ degree.
+const houghThetas = 720
+
+// HoughLines detects straight lines in an edge image (e.g. thresholded Sobel output) with the Hough transform.
+//
+// Each nonzero pixel votes for every line through it,
+// with θ quantized to ¼ degree, and ρ to 1 pixel.
+// Lines with more than threshold votes that are local maxima of the accumulator
+// are returned, sorted by decreasing votes.
+func HoughLines(edges *image.Gray, threshold int) []Line {
+	w, h := edges.Rect.Dx(), edges.Rect.Dy()
+	diag := int(math.Ceil(math.Hypot(float64(w), float64(h))))
+	rhos := 2*diag + 1
+
+	var sin, cos [houghThetas]float64
+	for t := range sin {
+		sin[t], cos[t] = math.Sincos(math.Pi * float64(t) / houghThetas)
+	}
+
+	acc := make([]int32, houghThetas*rhos)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if edges.Pix[y*edges.Stride+x] == 0 {
+				continue
+			}
+			for t := 0; t < houghThetas; t++ {
+				r := int(math.Round(float64(x)*cos[t]+float64(y)*sin[t])) + diag
+				acc[t*rhos+r]++
+			}
+		}
+	}
+
+	var lines []Line
+	for t := 0; t < houghThetas; t++ {
+		for r := 0; r < rhos; r++ {
+			v := acc[t*rhos+r]
+			if int(v) <= threshold || !houghPeak(acc, rhos, t, r) {
+				continue
+			}
+			lines = append(lines, Line{
+				Rho:   float64(r - diag),
+				Theta: math.Pi * float64(t) / houghThetas,
+				Votes: int(v),
+			})
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Votes > lines[j].Votes })
+	return lines
+}
+
+// houghPeak reports whether an accumulator cell is a local maximum of its 3×3 neighborhood.
+// Ties are broken in favor of the first cell in the accumulator.
+// Angles wrap around: θ+π is the same line as θ with ρ negated.
+func houghPeak(acc []int32, rhos, t, r int) bool {
+	v := acc[t*rhos+r]
+	for dt := -1; dt <= 1; dt++ {
+		for dr := -1; dr <= 1; dr++ {
+			nt, nr := t+dt, r+dr
+			switch {
+			case nt < 0:
+				nt, nr = houghThetas-1, rhos-1-nr
+			case nt >= houghThetas:
+				nt, nr = 0, rhos-1-nr
+			}
+			if nr < 0 || nr >= rhos || (dt == 0 && dr == 0) {
+				continue
+			}
+			n := acc[nt*rhos+nr]
+			if n > v || n == v && nt*rhos+nr < t*rhos+r {
+				return false
+			}
+		}
+	}
+	return true
+}
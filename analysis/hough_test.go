@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func Test_HoughLines(t *testing.T) {
+	for _, deg := range []float64{0, 10, 45, 90, 120, 179} {
+		theta := deg * math.Pi / 180
+		sin, cos := math.Sincos(theta)
+		rho := 50*cos + 40*sin // through the center
+
+		img := image.NewGray(image.Rect(5, 5, 105, 85))
+		for s := -200.0; s <= 200; s += 0.25 {
+			x := int(math.Round(rho*cos - s*sin))
+			y := int(math.Round(rho*sin + s*cos))
+			if x >= 0 && y >= 0 && x < 100 && y < 80 {
+				img.Pix[y*img.Stride+x] = 255
+			}
+		}
+
+		lines := HoughLines(img, 30)
+		if len(lines) == 0 {
+			t.Fatalf("%v°: no lines detected", deg)
+		}
+		got := lines[0]
+		if math.Abs(got.Theta-theta) > math.Pi/180 || math.Abs(got.Rho-rho) > 1 {
+			t.Errorf("%v°: expected: %v %v, got: %v %v", deg, rho, theta, got.Rho, got.Theta)
+		}
+	}
+}
+
+func Test_HoughLines_empty(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 30, 20))
+	if lines := HoughLines(img, 0); len(lines) != 0 {
+		t.Errorf("unexpected lines: %v", lines)
+	}
+}
@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"image"
+	"math"
+)
+
+// MatchTemplate locates a template within an image using normalized cross-correlation.
+//
+// It returns the location (in image coordinates) of the top-left corner of the best match, and its score in [-1, 1].
+// Flat regions, where the correlation is undefined, score 0.
+// If the template is larger than the image, it returns the image's minimum point and a score of -1.
+// Image means and variances are computed with integral images.
+func MatchTemplate(img, tmpl *image.Gray) (best image.Point, score float64) {
+	src := toGray(img)
+	tpl := toGray(tmpl)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	tw, th := tpl.Rect.Dx(), tpl.Rect.Dy()
+
+	best, score = img.Rect.Min, -1
+	if tw > w || th > h || tw <= 0 || th <= 0 {
+		return best, score
+	}
+
+	// zero-mean template
+	n := float64(tw * th)
+	var mean float64
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			mean += float64(tpl.Pix[y*tpl.Stride+x])
+		}
+	}
+	mean /= n
+
+	tz := make([]float64, tw*th)
+	var tvar float64
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			d := float64(tpl.Pix[y*tpl.Stride+x]) - mean
+			tz[y*tw+x] = d
+			tvar += d * d
+		}
+	}
+
+	sum := integral(src)
+	sqr := integralSquares(src)
+
+	for v := 0; v+th <= h; v++ {
+		for u := 0; u+tw <= w; u++ {
+			s := float64(sum.rect(u, v, u+tw, v+th))
+			s2 := float64(sqr.rect(u, v, u+tw, v+th))
+			ivar := s2 - s*s/n
+
+			var ncc float64
+			if ivar > 0 && tvar > 0 {
+				// the template is zero-mean, so the image mean cancels out
+				var num float64
+				for y := 0; y < th; y++ {
+					row := src.Pix[(v+y)*src.Stride+u:]
+					for x, t := range tz[y*tw : y*tw+tw] {
+						num += float64(row[x]) * t
+					}
+				}
+				ncc = max(-1, min(1, num/math.Sqrt(ivar*tvar)))
+			}
+
+			if ncc > score {
+				score = ncc
+				best = img.Rect.Min.Add(image.Pt(u, v))
+			}
+		}
+	}
+
+	return best, score
+}
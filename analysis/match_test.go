@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_MatchTemplate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	img := image.NewGray(image.Rect(10, 20, 74, 68))
+	rnd.Read(img.Pix)
+
+	want := image.Pt(37, 41)
+	tmpl := img.SubImage(image.Rect(want.X, want.Y, want.X+12, want.Y+9)).(*image.Gray)
+
+	best, score := MatchTemplate(img, tmpl)
+	if best != want {
+		t.Errorf("expected: %v, got: %v", want, best)
+	}
+	if math.Abs(score-1) > 1e-9 {
+		t.Errorf("expected score 1, got: %v", score)
+	}
+
+	// an inverted template anticorrelates everywhere
+	inv := image.NewGray(image.Rect(0, 0, 12, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 12; x++ {
+			inv.Pix[y*inv.Stride+x] = 255 - tmpl.GrayAt(want.X+x, want.Y+y).Y
+		}
+	}
+	if _, score := MatchTemplate(img, inv); score >= 1 {
+		t.Errorf("unexpected score: %v", score)
+	}
+
+	// too large
+	if best, score := MatchTemplate(tmpl, img); best != tmpl.Rect.Min || score != -1 {
+		t.Errorf("unexpected match: %v %v", best, score)
+	}
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import "image"
+
+// RowProfile returns the projection profile of an image onto its vertical axis, one value per row.
+//
+// If binarize is false, each value is the sum of the row's pixel values.
+// If binarize is true, each value counts the row's dark pixels (below 128),
+// e.g. the ink of a scanned document, for both grayscale and thresholded images.
+func RowProfile(img *image.Gray, binarize bool) []uint64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	res := make([]uint64, h)
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+w]
+		for _, p := range row {
+			res[y] += profileValue(p, binarize)
+		}
+	}
+	return res
+}
+
+// ColumnProfile returns the projection profile of an image onto its horizontal axis, one value per column.
+//
+// If binarize is false, each value is the sum of the column's pixel values.
+// If binarize is true, each value counts the column's dark pixels (below 128).
+func ColumnProfile(img *image.Gray, binarize bool) []uint64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	res := make([]uint64, w)
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+w]
+		for x, p := range row {
+			res[x] += profileValue(p, binarize)
+		}
+	}
+	return res
+}
+
+func profileValue(p uint8, binarize bool) uint64 {
+	if binarize {
+		if p < 128 {
+			return 1
+		}
+		return 0
+	}
+	return uint64(p)
+}
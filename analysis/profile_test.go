@@ -0,0 +1,42 @@
+package analysis
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func Test_Profile(t *testing.T) {
+	// dark horizontal stripes on rows 2-3 and 7, on a light gray background
+	img := image.NewGray(image.Rect(10, 10, 15, 20))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 5; x++ {
+			v := uint8(200)
+			if y == 2 || y == 3 || y == 7 {
+				v = 20
+			}
+			if x == 4 {
+				v = 100
+			}
+			img.Pix[y*img.Stride+x] = v
+		}
+	}
+
+	rows := RowProfile(img, true)
+	if exp := []uint64{1, 1, 5, 5, 1, 1, 1, 5, 1, 1}; !reflect.DeepEqual(rows, exp) {
+		t.Errorf("expected: %v, got: %v", exp, rows)
+	}
+	rows = RowProfile(img, false)
+	if exp := []uint64{900, 900, 80 + 100, 180, 900, 900, 900, 180, 900, 900}; !reflect.DeepEqual(rows, exp) {
+		t.Errorf("expected: %v, got: %v", exp, rows)
+	}
+
+	cols := ColumnProfile(img, true)
+	if exp := []uint64{3, 3, 3, 3, 10}; !reflect.DeepEqual(cols, exp) {
+		t.Errorf("expected: %v, got: %v", exp, cols)
+	}
+	cols = ColumnProfile(img, false)
+	if exp := []uint64{1460, 1460, 1460, 1460, 1000}; !reflect.DeepEqual(cols, exp) {
+		t.Errorf("expected: %v, got: %v", exp, cols)
+	}
+}
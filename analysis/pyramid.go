@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"image"
+)
+
+// SearchPyramid builds a Gaussian pyramid for coarse-to-fine search.
+//
+// The first level is img; each of the following levels is blurred and halved (rounding up)
+// from the previous one, and anchored at the origin.
+// At most levels images are returned: fewer if an image would shrink below 1×1.
+func SearchPyramid(img *image.Gray, levels int) []*image.Gray {
+	if levels <= 0 {
+		return nil
+	}
+	pyr := []*image.Gray{img}
+	for len(pyr) < levels {
+		last := pyr[len(pyr)-1]
+		if last.Rect.Dx() <= 1 || last.Rect.Dy() <= 1 {
+			break
+		}
+		pyr = append(pyr, pyrDown(last))
+	}
+	return pyr
+}
+
+// MatchTemplatePyramid locates a template within an image, like MatchTemplate,
+// searching a pyramid of at most levels levels from the top down.
+//
+// The whole of the coarsest level is searched, where the template is at least 4×4;
+// at each finer level only a small neighborhood of the previous match is searched.
+// This is much faster than MatchTemplate on large images,
+// but the coarse levels must preserve enough detail to locate the template.
+func MatchTemplatePyramid(img, tmpl *image.Gray, levels int) (best image.Point, score float64) {
+	tw, th := tmpl.Rect.Dx(), tmpl.Rect.Dy()
+	if tw > img.Rect.Dx() || th > img.Rect.Dy() || tw <= 0 || th <= 0 {
+		return img.Rect.Min, -1
+	}
+
+	// don't shrink the template below 4×4
+	for levels > 1 && (tw>>(levels-1) < 4 || th>>(levels-1) < 4) {
+		levels--
+	}
+	ipyr := SearchPyramid(img, levels)
+	tpyr := SearchPyramid(tmpl, len(ipyr))
+	levels = min(len(ipyr), len(tpyr))
+
+	// full search at the coarsest level, in coordinates relative to the image
+	top := ipyr[levels-1]
+	best, score = MatchTemplate(top, tpyr[levels-1])
+	best = best.Sub(top.Rect.Min)
+
+	const radius = 2
+	for l := levels - 2; l >= 0; l-- {
+		src, tpl := ipyr[l], tpyr[l]
+		p := best.Mul(2)
+
+		r := image.Rect(p.X-radius, p.Y-radius, p.X+radius+tpl.Rect.Dx(), p.Y+radius+tpl.Rect.Dy())
+		r = r.Add(src.Rect.Min).Intersect(src.Rect)
+		best, score = MatchTemplate(src.SubImage(r).(*image.Gray), tpl)
+		best = best.Sub(src.Rect.Min)
+	}
+
+	return img.Rect.Min.Add(best), score
+}
+
+// pyrDown blurs an image with a 5-tap binomial (Gaussian) kernel, and halves it.
+// Edge pixels are extended.
+func pyrDown(img *image.Gray) *image.Gray {
+	src := toGray(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := (w+1)/2, (h+1)/2
+
+	kernel := [5]int{1, 4, 6, 4, 1}
+
+	// horizontal pass, on every row
+	tmp := make([]int, dw*h)
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w]
+		for x := 0; x < dw; x++ {
+			var s int
+			for i, k := range kernel {
+				s += k * int(row[max(0, min(2*x+i-2, w-1))])
+			}
+			tmp[y*dw+x] = s
+		}
+	}
+
+	// vertical pass, on every other row
+	dst := image.NewGray(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var s int
+			for i, k := range kernel {
+				s += k * tmp[max(0, min(2*y+i-2, h-1))*dw+x]
+			}
+			dst.Pix[y*dst.Stride+x] = uint8((s + 128) / 256)
+		}
+	}
+	return dst
+}
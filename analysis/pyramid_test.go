@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func Test_SearchPyramid(t *testing.T) {
+	img := image.NewGray(image.Rect(3, 3, 40, 20))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+
+	pyr := SearchPyramid(img, 10)
+	sizes := []image.Point{{37, 17}, {19, 9}, {10, 5}, {5, 3}, {3, 2}, {2, 1}}
+	if len(pyr) != len(sizes) {
+		t.Fatalf("expected %d levels, got %d", len(sizes), len(pyr))
+	}
+	if pyr[0] != img {
+		t.Error("expected the first level to be the image")
+	}
+	for i, p := range pyr {
+		if p.Rect.Size() != sizes[i] {
+			t.Errorf("level %d: expected %v, got %v", i, sizes[i], p.Rect.Size())
+		}
+		// a flat image stays flat
+		for _, v := range p.Pix[:p.Rect.Dx()] {
+			if v != 200 {
+				t.Fatalf("level %d: expected 200, got %d", i, v)
+			}
+		}
+	}
+
+	if pyr := SearchPyramid(img, 2); len(pyr) != 2 {
+		t.Errorf("expected 2 levels, got %d", len(pyr))
+	}
+}
+
+func Test_MatchTemplatePyramid(t *testing.T) {
+	// smooth random texture: a coarse random grid, bilinearly interpolated, plus noise
+	rnd := rand.New(rand.NewSource(7))
+	const cell = 6
+	grid := make([]float64, 50*50)
+	for i := range grid {
+		grid[i] = rnd.Float64() * 200
+	}
+	img := image.NewGray(image.Rect(5, 7, 245, 207))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 240; x++ {
+			gx, gy := x/cell, y/cell
+			fx, fy := float64(x%cell)/cell, float64(y%cell)/cell
+			v := grid[gy*50+gx]*(1-fx)*(1-fy) + grid[gy*50+gx+1]*fx*(1-fy) +
+				grid[(gy+1)*50+gx]*(1-fx)*fy + grid[(gy+1)*50+gx+1]*fx*fy
+			img.Pix[y*img.Stride+x] = uint8(v + rnd.Float64()*40)
+		}
+	}
+
+	for _, want := range []image.Point{{101, 53}, {6, 8}, {200, 150}} {
+		tmpl := img.SubImage(image.Rect(want.X, want.Y, want.X+40, want.Y+30)).(*image.Gray)
+
+		brute, _ := MatchTemplate(img, tmpl)
+		best, score := MatchTemplatePyramid(img, tmpl, 4)
+		if best != brute || best != want {
+			t.Errorf("expected: %v, got: %v (brute force: %v)", want, best, brute)
+		}
+		if score < 0.999 {
+			t.Errorf("expected a perfect score, got: %v", score)
+		}
+	}
+
+	// too large
+	small := img.SubImage(image.Rect(10, 10, 20, 20)).(*image.Gray)
+	if best, score := MatchTemplatePyramid(small, img, 3); best != small.Rect.Min || score != -1 {
+		t.Errorf("unexpected match: %v %v", best, score)
+	}
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// rotate rotates an image counterclockwise by an arbitrary angle (in degrees) about its center,
+// using bilinear interpolation of premultiplied colors.
+// The result has the size of the source, anchored at the origin; exposed areas are transparent.
+func rotate(img image.Image, degrees float64) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+	dst := image.NewRGBA(src.Rect)
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	cx, cy := float64(w)/2, float64(h)/2
+
+	for y := 0; y < h; y++ {
+		dy := float64(y) + 0.5 - cy
+		for x := 0; x < w; x++ {
+			dx := float64(x) + 0.5 - cx
+
+			// source pixel coordinates
+			sx := dx*cos - dy*sin + cx - 0.5
+			sy := dx*sin + dy*cos + cy - 0.5
+
+			x0, y0 := math.Floor(sx), math.Floor(sy)
+			fx, fy := sx-x0, sy-y0
+			ix, iy := int(x0), int(y0)
+
+			var c [4]float64
+			sample := func(x, y int, f float64) {
+				if f == 0 || x < 0 || y < 0 || x >= w || y >= h {
+					return
+				}
+				i := y*src.Stride + 4*x
+				for j := range c {
+					c[j] += f * float64(src.Pix[i+j])
+				}
+			}
+			sample(ix, iy, (1-fx)*(1-fy))
+			sample(ix+1, iy, fx*(1-fy))
+			sample(ix, iy+1, (1-fx)*fy)
+			sample(ix+1, iy+1, fx*fy)
+
+			i := y*dst.Stride + 4*x
+			for j := range c {
+				dst.Pix[i+j] = uint8(c[j] + 0.5)
+			}
+		}
+	}
+
+	return dst
+}
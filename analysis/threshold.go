@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ThresholdAdaptive thresholds an image against the mean of each pixel's local neighborhood,
+// which copes with uneven lighting (e.g. in scanned documents).
+//
+// The image is converted to grayscale. Pixels brighter than the mean of their
+// window×window neighborhood minus c become 255, other pixels become 0.
+// The neighborhood shrinks at the edges of the image.
+// Means are computed with an integral image, in constant time per pixel.
+func ThresholdAdaptive(img image.Image, window int, c int) *image.Gray {
+	src := toGray(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewGray(src.Rect)
+
+	sum := integral(src)
+	radius := window / 2
+
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, w)
+
+			n := int64((x1 - x0) * (y1 - y0))
+			s := sum.rect(x0, y0, x1, y1)
+			v := int64(src.Pix[y*src.Stride+x])
+
+			// v > s/n - c, without division
+			if (v+int64(c))*n > s {
+				dst.Pix[y*dst.Stride+x] = 255
+			}
+		}
+	}
+
+	return dst
+}
+
+// integralImage holds the sums of all pixels above and to the left of each pixel.
+type integralImage struct {
+	sum    []int64
+	stride int
+}
+
+func integral(img *image.Gray) integralImage {
+	return integralOf(img, func(v int64) int64 { return v })
+}
+
+// integralSquares holds the sums of the squares of the pixels.
+func integralSquares(img *image.Gray) integralImage {
+	return integralOf(img, func(v int64) int64 { return v * v })
+}
+
+func integralOf(img *image.Gray, f func(int64) int64) integralImage {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	res := integralImage{make([]int64, (w+1)*(h+1)), w + 1}
+
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			row += f(int64(img.Pix[y*img.Stride+x]))
+			res.sum[(y+1)*res.stride+x+1] = res.sum[y*res.stride+x+1] + row
+		}
+	}
+	return res
+}
+
+// integralSlice holds the sums of a w×h row-major array of values.
+func integralSlice(v []int64, w, h int) integralImage {
+	res := integralImage{make([]int64, (w+1)*(h+1)), w + 1}
+
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			row += v[y*w+x]
+			res.sum[(y+1)*res.stride+x+1] = res.sum[y*res.stride+x+1] + row
+		}
+	}
+	return res
+}
+
+// rect sums the pixels in [x0, x1[ × [y0, y1[.
+func (s integralImage) rect(x0, y0, x1, y1 int) int64 {
+	return s.sum[y1*s.stride+x1] - s.sum[y0*s.stride+x1] - s.sum[y1*s.stride+x0] + s.sum[y0*s.stride+x0]
+}
+
+// toGray converts an image to a grayscale image anchored at the origin.
+// The returned image may not be a copy.
+func toGray(img image.Image) *image.Gray {
+	if img, ok := img.(*image.Gray); ok && img.Rect.Min == image.ZP {
+		return img
+	}
+	bounds := img.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_ThresholdAdaptive(t *testing.T) {
+	// dark dots on a background lit from the left
+	img := image.NewGray(image.Rect(0, 0, 100, 40))
+	ink := func(x, y int) bool { return x%10 >= 4 && x%10 < 6 && y%10 >= 4 && y%10 < 6 }
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 100; x++ {
+			v := 60 + 19*x/10
+			if ink(x, y) {
+				v -= 50
+			}
+			img.Pix[y*img.Stride+x] = uint8(v)
+		}
+	}
+
+	count := func(dst *image.Gray) (errs int) {
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 100; x++ {
+				if (dst.Pix[y*dst.Stride+x] == 0) != ink(x, y) {
+					errs++
+				}
+			}
+		}
+		return errs
+	}
+
+	// every global threshold fails
+	best := len(img.Pix)
+	for th := 0; th < 256; th++ {
+		dst := image.NewGray(img.Rect)
+		for i, p := range img.Pix {
+			if int(p) > th {
+				dst.Pix[i] = 255
+			}
+		}
+		if errs := count(dst); errs < best {
+			best = errs
+		}
+	}
+	if best == 0 {
+		t.Error("global threshold did not fail")
+	}
+
+	dst := ThresholdAdaptive(img, 9, 10)
+	if errs := count(dst); errs != 0 {
+		t.Errorf("adaptive threshold failed for %d pixels", errs)
+	}
+}
+
+func Test_Integral(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 7, 5))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+
+	sum := integral(img)
+	for y0 := 0; y0 <= 5; y0++ {
+		for y1 := y0; y1 <= 5; y1++ {
+			for x0 := 0; x0 <= 7; x0++ {
+				for x1 := x0; x1 <= 7; x1++ {
+					var exp int64
+					for y := y0; y < y1; y++ {
+						for x := x0; x < x1; x++ {
+							exp += int64(img.Pix[y*7+x])
+						}
+					}
+					if res := sum.rect(x0, y0, x1, y1); res != exp {
+						t.Fatalf("at %d,%d-%d,%d, expected: %d, got: %d", x0, y0, x1, y1, exp, res)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package compose
+
+import (
+	"image"
+)
+
+// AutoCropBars detects uniform bars (e.g. letterboxing, or scanner margins) along the edges of an image,
+// and returns the rectangle of content inside them.
+//
+// Each edge is checked independently: if all the pixels of its outermost row (or column)
+// are within tol (per 8-bit channel) of their average color, that is the bar color,
+// and every row (or column) that matches it, going inwards, is part of the bar.
+// If the whole image is uniform, an empty rectangle is returned.
+func AutoCropBars(img image.Image, tol uint8) image.Rectangle {
+	bounds := img.Bounds()
+	src := crop(img, bounds)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if w <= 0 || h <= 0 {
+		return image.Rectangle{}
+	}
+
+	// rows span all columns; columns span the rows between the top and bottom bars
+	y0, y1 := 0, h
+	line := func(i int, horizontal bool) (start, step, n int) {
+		if horizontal {
+			return i * src.Stride, 4, w
+		}
+		return y0*src.Stride + 4*i, src.Stride, y1 - y0
+	}
+
+	average := func(i int, horizontal bool) (c [4]uint8) {
+		start, step, n := line(i, horizontal)
+		var sum [4]int
+		for j := range n {
+			for k := range sum {
+				sum[k] += int(src.Pix[start+j*step+k])
+			}
+		}
+		for k := range c {
+			c[k] = uint8((sum[k] + n/2) / n)
+		}
+		return c
+	}
+
+	matches := func(i int, horizontal bool, c [4]uint8) bool {
+		start, step, n := line(i, horizontal)
+		for j := range n {
+			for k := range c {
+				if d := int(src.Pix[start+j*step+k]) - int(c[k]); d > int(tol) || -d > int(tol) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	// bar counts the lines of a bar, scanning from first towards last
+	bar := func(first, last int, horizontal bool) int {
+		c := average(first, horizontal)
+		dir := 1
+		if last < first {
+			dir = -1
+		}
+		n := 0
+		for i := first; i != last+dir && matches(i, horizontal, c); i += dir {
+			n++
+		}
+		return n
+	}
+
+	top := bar(0, h-1, true)
+	if top == h {
+		return image.Rectangle{}
+	}
+	bottom := bar(h-1, top, true)
+	y0, y1 = top, h-bottom
+	if y0 >= y1 {
+		return image.Rectangle{}
+	}
+	left := bar(0, w-1, false)
+	if left == w {
+		return image.Rectangle{}
+	}
+	right := bar(w-1, left, false)
+
+	return image.Rect(bounds.Min.X+left, bounds.Min.Y+top, bounds.Max.X-right, bounds.Max.Y-bottom)
+}
+
+// AutoCropBarsImage crops the uniform bars detected by AutoCropBars off an image.
+// The result is a copy anchored at the origin.
+func AutoCropBarsImage(img image.Image, tol uint8) *image.RGBA {
+	return crop(img, AutoCropBars(img, tol))
+}
+
+// AutoTrim detects the background around the content of an image,
+// and returns the smallest rectangle that contains everything else.
+//
+// If the image has any transparent pixels, the background is transparency:
+// pixels with alpha up to tol are trimmed.
+// Otherwise, the background is the color of most of the four corners,
+// and pixels within tol of it (per 8-bit channel) are trimmed.
+// If the whole image is background, an empty rectangle is returned.
+func AutoTrim(img image.Image, tol uint8) image.Rectangle {
+	bounds := img.Bounds()
+	src := crop(img, bounds)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if w <= 0 || h <= 0 {
+		return image.Rectangle{}
+	}
+
+	near := func(i int, c [4]uint8) bool {
+		for k := range c {
+			if d := int(src.Pix[i+k]) - int(c[k]); d > int(tol) || -d > int(tol) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var background func(i int) bool
+	if !src.Opaque() {
+		background = func(i int) bool { return src.Pix[i+3] <= tol }
+	} else {
+		corners := [4]int{
+			0,
+			4 * (w - 1),
+			(h - 1) * src.Stride,
+			(h-1)*src.Stride + 4*(w-1),
+		}
+		best, votes := corners[0], 0
+		for _, i := range corners {
+			var c [4]uint8
+			copy(c[:], src.Pix[i:])
+			n := 0
+			for _, j := range corners {
+				if near(j, c) {
+					n++
+				}
+			}
+			if n > votes {
+				best, votes = i, n
+			}
+		}
+		var bg [4]uint8
+		copy(bg[:], src.Pix[best:])
+		background = func(i int) bool { return near(i, bg) }
+	}
+
+	var r image.Rectangle
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !background(y*src.Stride + 4*x) {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if r.Empty() {
+		return r
+	}
+	return r.Add(bounds.Min)
+}
+
+// Gravity specifies which part of an image to keep when cropping it.
+type Gravity int
+
+const (
+	Center Gravity = iota
+	North
+	South
+	East
+	West
+	NorthEast
+	NorthWest
+	SouthEast
+	SouthWest
+)
+
+// CropToAspect crops an image to the largest rectangle with an aw:ah aspect ratio,
+// placed according to gravity (e.g. North keeps the top, centered horizontally).
+// The result is a copy anchored at the origin.
+func CropToAspect(img image.Image, aw, ah int, gravity Gravity) image.Image {
+	if aw <= 0 || ah <= 0 {
+		panic("Invalid aspect ratio")
+	}
+	if gravity < Center || gravity > SouthWest {
+		panic("Unknown gravity")
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	cw, ch := w, h
+	if w*ah > h*aw {
+		cw = h * aw / ah
+	} else {
+		ch = w * ah / aw
+	}
+
+	x, y := (w-cw)/2, (h-ch)/2
+	switch gravity {
+	case North, NorthEast, NorthWest:
+		y = 0
+	case South, SouthEast, SouthWest:
+		y = h - ch
+	}
+	switch gravity {
+	case East, NorthEast, SouthEast:
+		x = w - cw
+	case West, NorthWest, SouthWest:
+		x = 0
+	}
+
+	r := image.Rect(x, y, x+cw, y+ch).Add(bounds.Min)
+	return crop(img, r)
+}
@@ -0,0 +1,141 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_AutoCropBars(t *testing.T) {
+	// a noisy frame, letterboxed with (slightly noisy) black bars,
+	// and a white pillar on the right
+	rnd := rand.New(rand.NewSource(3))
+	img := image.NewRGBA(image.Rect(10, 20, 110, 80))
+	content := image.Rect(14, 29, 105, 71)
+	for y := 20; y < 80; y++ {
+		for x := 10; x < 110; x++ {
+			var c color.RGBA
+			switch {
+			case image.Pt(x, y).In(content):
+				c = color.RGBA{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), 255}
+			case x >= content.Max.X && y >= content.Min.Y && y < content.Max.Y:
+				c = color.RGBA{255, 255, 255, 255}
+			default:
+				v := uint8(rnd.Intn(4))
+				c = color.RGBA{v, v, v, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	if r := AutoCropBars(img, 4); r != content {
+		t.Errorf("expected: %v, got: %v", content, r)
+	}
+	// too strict for the noise in the bars
+	if r := AutoCropBars(img, 0); r.Min.Y != 20 {
+		t.Errorf("expected no top bar, got: %v", r)
+	}
+
+	dst := AutoCropBarsImage(img, 4)
+	if r := image.Rect(0, 0, content.Dx(), content.Dy()); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+	if dst.RGBAAt(0, 0) != img.RGBAAt(content.Min.X, content.Min.Y) {
+		t.Error("colors don't match")
+	}
+
+	// a uniform image is all bars
+	if r := AutoCropBars(&image.Gray{Pix: make([]uint8, 25), Stride: 5, Rect: image.Rect(0, 0, 5, 5)}, 0); !r.Empty() {
+		t.Errorf("expected an empty rectangle, got: %v", r)
+	}
+}
+
+func Test_AutoTrim(t *testing.T) {
+	t.Run("Alpha", func(t *testing.T) {
+		// a sprite, with an almost transparent glow around it
+		img := image.NewNRGBA(image.Rect(5, 5, 45, 35))
+		content := image.Rect(12, 10, 30, 31)
+		for y := content.Min.Y; y < content.Max.Y; y++ {
+			for x := content.Min.X; x < content.Max.X; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{0, 0, 0, 255})
+			}
+		}
+		img.SetNRGBA(6, 6, color.NRGBA{255, 0, 0, 3})
+
+		if r := AutoTrim(img, 8); r != content {
+			t.Errorf("expected: %v, got: %v", content, r)
+		}
+		if r := AutoTrim(img, 0); r != content.Union(image.Rect(6, 6, 7, 7)) {
+			t.Errorf("expected the glow, got: %v", r)
+		}
+	})
+
+	t.Run("Color", func(t *testing.T) {
+		// a photo, on a slightly noisy white background,
+		// touching the bottom right corner
+		rnd := rand.New(rand.NewSource(5))
+		img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+		content := image.Rect(20, 15, 60, 40)
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 60; x++ {
+				v := uint8(250 + rnd.Intn(6))
+				c := color.RGBA{v, v, v, 255}
+				if image.Pt(x, y).In(content) {
+					c = color.RGBA{uint8(rnd.Intn(200)), uint8(rnd.Intn(200)), uint8(rnd.Intn(200)), 255}
+				}
+				img.SetRGBA(x, y, c)
+			}
+		}
+
+		if r := AutoTrim(img, 6); r != content {
+			t.Errorf("expected: %v, got: %v", content, r)
+		}
+	})
+
+	// a uniform image is all background
+	if r := AutoTrim(&image.Gray{Pix: make([]uint8, 25), Stride: 5, Rect: image.Rect(0, 0, 5, 5)}, 0); !r.Empty() {
+		t.Errorf("expected an empty rectangle, got: %v", r)
+	}
+}
+
+func Test_CropToAspect(t *testing.T) {
+	// each pixel encodes its coordinates
+	img := image.NewRGBA(image.Rect(5, 5, 45, 25))
+	for y := 5; y < 25; y++ {
+		for x := 5; x < 45; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	tests := []struct {
+		aw, ah  int
+		gravity Gravity
+		want    image.Rectangle
+	}{
+		{1, 1, Center, image.Rect(15, 5, 35, 25)},
+		{1, 1, North, image.Rect(15, 5, 35, 25)},
+		{1, 1, East, image.Rect(25, 5, 45, 25)},
+		{1, 1, West, image.Rect(5, 5, 25, 25)},
+		{1, 1, SouthEast, image.Rect(25, 5, 45, 25)},
+		{1, 1, NorthWest, image.Rect(5, 5, 25, 25)},
+		{4, 1, Center, image.Rect(5, 10, 45, 20)},
+		{4, 1, North, image.Rect(5, 5, 45, 15)},
+		{4, 1, South, image.Rect(5, 15, 45, 25)},
+		{4, 1, NorthEast, image.Rect(5, 5, 45, 15)},
+		{4, 1, SouthWest, image.Rect(5, 15, 45, 25)},
+		{4, 1, West, image.Rect(5, 10, 45, 20)},
+		{2, 1, Center, image.Rect(5, 5, 45, 25)},
+		{3, 2, South, image.Rect(10, 5, 40, 25)},
+	}
+	for _, tt := range tests {
+		dst := CropToAspect(img, tt.aw, tt.ah, tt.gravity).(*image.RGBA)
+		if size := tt.want.Size(); dst.Rect != (image.Rectangle{Max: size}) {
+			t.Errorf("%d:%d/%d: expected size %v, got: %v", tt.aw, tt.ah, tt.gravity, size, dst.Rect)
+			continue
+		}
+		if c := dst.RGBAAt(0, 0); int(c.R) != tt.want.Min.X || int(c.G) != tt.want.Min.Y {
+			t.Errorf("%d:%d/%d: expected %v, got %d,%d", tt.aw, tt.ah, tt.gravity, tt.want.Min, c.R, c.G)
+		}
+	}
+}
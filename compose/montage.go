@@ -0,0 +1,44 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Montage arranges images into a grid with cols columns, such as a contact sheet.
+//
+// All cells have the size of the largest image, and each image is centered in its cell.
+// Cells are separated from each other, and from the edges, by padding pixels of the background color.
+// Images are drawn over the background, in row-major order; the last row may be partial.
+func Montage(images []image.Image, cols, padding int, bg color.Color) *image.RGBA {
+	if cols <= 0 {
+		panic("Invalid column count")
+	}
+	if len(images) == 0 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+
+	var cell image.Point
+	for _, img := range images {
+		size := img.Bounds().Size()
+		cell.X = max(cell.X, size.X)
+		cell.Y = max(cell.Y, size.Y)
+	}
+
+	rows := (len(images) + cols - 1) / cols
+	dst := image.NewRGBA(image.Rect(0, 0,
+		cols*(cell.X+padding)+padding,
+		rows*(cell.Y+padding)+padding))
+	draw.Draw(dst, dst.Rect, image.NewUniform(bg), image.Point{}, draw.Src)
+
+	for i, img := range images {
+		bounds := img.Bounds()
+		col, row := i%cols, i/cols
+		min := image.Pt(
+			padding+col*(cell.X+padding)+(cell.X-bounds.Dx())/2,
+			padding+row*(cell.Y+padding)+(cell.Y-bounds.Dy())/2)
+		draw.Draw(dst, image.Rectangle{min, min.Add(bounds.Size())}, img, bounds.Min, draw.Over)
+	}
+	return dst
+}
@@ -0,0 +1,53 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func Test_Montage(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	bg := color.RGBA{10, 10, 10, 255}
+
+	uniform := func(c color.Color, r image.Rectangle) image.Image {
+		img := image.NewRGBA(r)
+		draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+		return img
+	}
+
+	images := []image.Image{
+		uniform(red, image.Rect(0, 0, 10, 6)),
+		uniform(green, image.Rect(5, 5, 9, 13)), // 4x8, offset
+		uniform(blue, image.Rect(0, 0, 2, 2)),
+	}
+
+	// 2 columns, cells of 10x8, padding of 3
+	dst := Montage(images, 2, 3, bg)
+	if r := image.Rect(0, 0, 2*13+3, 2*11+3); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+
+	tests := []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		{0, 0, bg},
+		{3, 4, red},    // top-left of red, centered vertically
+		{12, 9, red},   // bottom-right of red
+		{3, 3, bg},     // above red
+		{19, 3, green}, // green, centered horizontally
+		{22, 10, green},
+		{18, 3, bg},
+		{7, 17, blue}, // blue, centered in the partial row
+		{19, 17, bg},  // empty cell
+	}
+	for _, tt := range tests {
+		if c := dst.RGBAAt(tt.x, tt.y); c != tt.c {
+			t.Errorf("at %dx%d, expected: %v, got: %v", tt.x, tt.y, tt.c, c)
+		}
+	}
+}
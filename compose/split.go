@@ -0,0 +1,49 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// SplitVertical splits an image down the middle into left and right halves, e.g. a stereo pair.
+// For odd widths, the left half gets the extra column.
+// Halves are returned as copies anchored at the origin.
+func SplitVertical(img image.Image) (left, right image.Image) {
+	bounds := img.Bounds()
+	mid := bounds.Min.X + (bounds.Dx()+1)/2
+	left = crop(img, image.Rect(bounds.Min.X, bounds.Min.Y, mid, bounds.Max.Y))
+	right = crop(img, image.Rect(mid, bounds.Min.Y, bounds.Max.X, bounds.Max.Y))
+	return left, right
+}
+
+// SplitHorizontal splits an image across the middle into top and bottom halves.
+// For odd heights, the top half gets the extra row.
+// Halves are returned as copies anchored at the origin.
+func SplitHorizontal(img image.Image) (top, bottom image.Image) {
+	bounds := img.Bounds()
+	mid := bounds.Min.Y + (bounds.Dy()+1)/2
+	top = crop(img, image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, mid))
+	bottom = crop(img, image.Rect(bounds.Min.X, mid, bounds.Max.X, bounds.Max.Y))
+	return top, bottom
+}
+
+// JoinHorizontal places two images side by side, separated by a gap of gap pixels, e.g. for before/after comparisons.
+// Images are aligned to the top, and drawn over the background color.
+func JoinHorizontal(a, b image.Image, gap int, bg color.Color) *image.RGBA {
+	ab, bb := a.Bounds(), b.Bounds()
+	gap = max(0, gap)
+
+	dst := image.NewRGBA(image.Rect(0, 0, ab.Dx()+gap+bb.Dx(), max(ab.Dy(), bb.Dy())))
+	draw.Draw(dst, dst.Rect, image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(0, 0, ab.Dx(), ab.Dy()), a, ab.Min, draw.Over)
+	draw.Draw(dst, image.Rect(ab.Dx()+gap, 0, dst.Rect.Dx(), bb.Dy()), b, bb.Min, draw.Over)
+	return dst
+}
+
+// crop copies part of an image into an RGBA image anchored at the origin.
+func crop(img image.Image, r image.Rectangle) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Rect, img, r.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,49 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_Split(t *testing.T) {
+	img := image.NewRGBA(image.Rect(3, 5, 24, 16))
+	rand.New(rand.NewSource(42)).Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	left, right := SplitVertical(img)
+	if l, r := left.Bounds(), right.Bounds(); l != image.Rect(0, 0, 11, 11) || r != image.Rect(0, 0, 10, 11) {
+		t.Fatalf("unexpected halves: %v, %v", l, r)
+	}
+	top, bottom := SplitHorizontal(img)
+	if t0, b := top.Bounds(), bottom.Bounds(); t0 != image.Rect(0, 0, 21, 6) || b != image.Rect(0, 0, 21, 5) {
+		t.Fatalf("unexpected halves: %v, %v", t0, b)
+	}
+
+	bg := color.RGBA{1, 2, 3, 255}
+	for _, gap := range []int{0, 4} {
+		dst := JoinHorizontal(left, right, gap, bg)
+		if r := image.Rect(0, 0, 21+gap, 11); dst.Rect != r {
+			t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+		}
+		for y := 0; y < 11; y++ {
+			for x := 0; x < 21+gap; x++ {
+				var exp color.Color
+				switch {
+				case x < 11:
+					exp = img.At(x+3, y+5)
+				case x < 11+gap:
+					exp = bg
+				default:
+					exp = img.At(x-gap+3, y+5)
+				}
+				if c := dst.At(x, y); c != exp {
+					t.Fatalf("gap %d, at %dx%d, expected: %v, got: %v", gap, x, y, exp, c)
+				}
+			}
+		}
+	}
+}
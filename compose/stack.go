@@ -0,0 +1,167 @@
+package compose
+
+import (
+	"errors"
+	"image"
+	"slices"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Average averages a stack of same-size images (e.g. to reduce noise, or to blend exposures).
+//
+// Images are averaged in linear light, with premultiplied alpha, with the given per-image weights,
+// or with equal weights if weights is nil.
+// The result is anchored at the origin.
+func Average(imgs []image.Image, weights []float64) (*image.RGBA, error) {
+	size, err := stackSize(imgs)
+	if err != nil {
+		return nil, err
+	}
+	if weights == nil {
+		weights = make([]float64, len(imgs))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(imgs) {
+		return nil, errors.New("compose: weight count doesn't match image count")
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return nil, errors.New("compose: weights must have a positive sum")
+	}
+
+	dst := imageutil.NewLinearRGBA(image.Rectangle{Max: size})
+	for i, img := range imgs {
+		src := imageutil.ToLinearFloat(img)
+		w := float32(weights[i] / total)
+		for j, v := range src.Pix {
+			dst.Pix[j] += w * v
+		}
+	}
+	return imageutil.ToRGBAPremultiplied(imageutil.FromLinearFloat(dst)), nil
+}
+
+// CompositeByWeight blends a stack of same-size images with per-pixel weight maps
+// (e.g. sharpness maps for focus stacking, or well-exposedness maps for exposure fusion).
+//
+// At each pixel, weights are normalized across the stack;
+// where all weights are zero, images are weighted equally.
+// Images are blended in linear light, with premultiplied alpha.
+// The result is anchored at the origin.
+func CompositeByWeight(imgs []image.Image, weights []*image.Gray) (*image.RGBA, error) {
+	size, err := stackSize(imgs)
+	if err != nil {
+		return nil, err
+	}
+	if len(weights) != len(imgs) {
+		return nil, errors.New("compose: weight map count doesn't match image count")
+	}
+	for _, w := range weights {
+		if w == nil {
+			return nil, errors.New("compose: nil weight map")
+		}
+		if w.Rect.Size() != size {
+			return nil, errors.New("compose: weight map size doesn't match image size")
+		}
+	}
+
+	srcs := make([]*imageutil.LinearRGBA, len(imgs))
+	for i, img := range imgs {
+		srcs[i] = imageutil.ToLinearFloat(img)
+	}
+
+	dst := imageutil.NewLinearRGBA(image.Rectangle{Max: size})
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			var total int
+			for _, w := range weights {
+				total += int(w.Pix[y*w.Stride+x])
+			}
+
+			d := dst.Pix[dst.PixOffset(x, y):][:4]
+			for i, src := range srcs {
+				f := float32(1) / float32(len(srcs))
+				if total > 0 {
+					f = float32(weights[i].Pix[y*weights[i].Stride+x]) / float32(total)
+				}
+				s := src.Pix[src.PixOffset(x, y):][:4]
+				for k := range d {
+					d[k] += f * s[k]
+				}
+			}
+		}
+	}
+	return imageutil.ToRGBAPremultiplied(imageutil.FromLinearFloat(dst)), nil
+}
+
+// StackMedian computes the per-channel median of a stack of same-size images
+// (e.g. to remove transient objects from a set of photos of the same scene).
+// Channels are computed independently, on premultiplied sRGB values.
+// For an even number of images, the two middle values are averaged.
+// The result is anchored at the origin.
+func StackMedian(imgs []image.Image) (*image.RGBA, error) {
+	return stackReduce(imgs, func(v []uint8) uint8 {
+		slices.Sort(v)
+		n := len(v)
+		if n%2 != 0 {
+			return v[n/2]
+		}
+		return uint8((int(v[n/2-1]) + int(v[n/2]) + 1) / 2)
+	})
+}
+
+// StackMin computes the per-channel minimum of a stack of same-size images.
+// The result is anchored at the origin.
+func StackMin(imgs []image.Image) (*image.RGBA, error) {
+	return stackReduce(imgs, slices.Min[[]uint8])
+}
+
+// StackMax computes the per-channel maximum of a stack of same-size images.
+// The result is anchored at the origin.
+func StackMax(imgs []image.Image) (*image.RGBA, error) {
+	return stackReduce(imgs, slices.Max[[]uint8])
+}
+
+// stackReduce reduces each channel of each pixel across a stack with f,
+// which may reorder its argument.
+func stackReduce(imgs []image.Image, f func([]uint8) uint8) (*image.RGBA, error) {
+	size, err := stackSize(imgs)
+	if err != nil {
+		return nil, err
+	}
+
+	srcs := make([]*image.RGBA, len(imgs))
+	for i, img := range imgs {
+		srcs[i] = crop(img, img.Bounds())
+	}
+
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	vals := make([]uint8, len(srcs))
+	for i := range dst.Pix {
+		for j, src := range srcs {
+			vals[j] = src.Pix[i]
+		}
+		dst.Pix[i] = f(vals)
+	}
+	return dst, nil
+}
+
+// stackSize checks that a stack has images, all of the same size, and returns that size.
+func stackSize(imgs []image.Image) (image.Point, error) {
+	if len(imgs) == 0 {
+		return image.Point{}, errors.New("compose: no images")
+	}
+	size := imgs[0].Bounds().Size()
+	for _, img := range imgs[1:] {
+		if img.Bounds().Size() != size {
+			return image.Point{}, errors.New("compose: image sizes don't match")
+		}
+	}
+	return size, nil
+}
@@ -0,0 +1,166 @@
+package compose
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Average(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	b := image.NewRGBA(image.Rect(5, 5, 9, 8))
+	for i := 0; i < len(a.Pix); i += 4 {
+		copy(a.Pix[i:], []uint8{0, 0, 0, 255})
+		copy(b.Pix[i:], []uint8{255, 255, 255, 255})
+	}
+
+	dst, err := Average([]image.Image{a, b}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := image.Rect(0, 0, 4, 3); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+	// the linear light midpoint of black and white is 188, not 128
+	if c := dst.RGBAAt(2, 1); c != (color.RGBA{188, 188, 188, 255}) {
+		t.Errorf("expected the linear light midpoint, got %v", c)
+	}
+
+	// weights
+	dst, err = Average([]image.Image{a, b}, []float64{3, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := dst.RGBAAt(0, 0); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected black, got %v", c)
+	}
+
+	// validation
+	if _, err := Average(nil, nil); err == nil {
+		t.Error("expected an error for an empty stack")
+	}
+	if _, err := Average([]image.Image{a, b}, []float64{1}); err == nil {
+		t.Error("expected an error for a weight count mismatch")
+	}
+	if _, err := Average([]image.Image{a, b}, []float64{1, -1}); err == nil {
+		t.Error("expected an error for zero weights")
+	}
+	if _, err := Average([]image.Image{a, a.SubImage(image.Rect(0, 0, 3, 3))}, nil); err == nil {
+		t.Error("expected an error for a size mismatch")
+	}
+}
+
+func Test_StackMedian(t *testing.T) {
+	// three photos of a gradient, one with a transient white object
+	imgs := make([]image.Image, 3)
+	for i := range imgs {
+		img := image.NewRGBA(image.Rect(i, i, 8+i, 6+i))
+		for y := 0; y < 6; y++ {
+			for x := 0; x < 8; x++ {
+				v := uint8(30*x + i)
+				img.SetRGBA(x+i, y+i, color.RGBA{v, v, v, 255})
+			}
+		}
+		imgs[i] = img
+	}
+	imgs[1].(*image.RGBA).SetRGBA(4, 3, color.RGBA{255, 255, 255, 255})
+
+	dst, err := StackMedian(imgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 8; x++ {
+			v := uint8(30*x + 1)
+			if x == 3 && y == 2 {
+				v = 92 // the outlier is dropped
+			}
+			if c := dst.RGBAAt(x, y); c != (color.RGBA{v, v, v, 255}) {
+				t.Errorf("at %dx%d: expected %d, got %v", x, y, v, c)
+			}
+		}
+	}
+
+	// an even count averages the middle values
+	dst, err = StackMedian(imgs[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := dst.RGBAAt(1, 0); c != (color.RGBA{31, 31, 31, 255}) {
+		t.Errorf("expected 31, got %v", c)
+	}
+
+	dst, err = StackMin(imgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := dst.RGBAAt(3, 2); c != (color.RGBA{90, 90, 90, 255}) {
+		t.Errorf("expected 90, got %v", c)
+	}
+
+	dst, err = StackMax(imgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := dst.RGBAAt(3, 2); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected 255, got %v", c)
+	}
+
+	if _, err := StackMax([]image.Image{imgs[0], image.NewRGBA(image.Rect(0, 0, 1, 1))}); err == nil {
+		t.Error("expected an error for a size mismatch")
+	}
+}
+
+func Test_CompositeByWeight(t *testing.T) {
+	red := image.NewRGBA(image.Rect(0, 0, 6, 4))
+	blue := image.NewRGBA(image.Rect(2, 2, 8, 6))
+	for i := 0; i < len(red.Pix); i += 4 {
+		copy(red.Pix[i:], []uint8{255, 0, 0, 255})
+		copy(blue.Pix[i:], []uint8{0, 0, 255, 255})
+	}
+
+	// select red on the left, blue on the right, and blend equally on the last row
+	wr := image.NewGray(image.Rect(0, 0, 6, 4))
+	wb := image.NewGray(image.Rect(1, 1, 7, 5))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 6; x++ {
+			if x < 3 {
+				wr.Pix[y*wr.Stride+x] = 200
+			} else {
+				wb.Pix[y*wb.Stride+x] = 10
+			}
+		}
+	}
+
+	dst, err := CompositeByWeight([]image.Image{red, blue}, []*image.Gray{wr, wb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := image.Rect(0, 0, 6, 4); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			want := color.RGBA{255, 0, 0, 255}
+			switch {
+			case y == 3:
+				want = color.RGBA{188, 0, 188, 255}
+			case x >= 3:
+				want = color.RGBA{0, 0, 255, 255}
+			}
+			if c := dst.RGBAAt(x, y); c != want {
+				t.Errorf("at %dx%d: expected %v, got %v", x, y, want, c)
+			}
+		}
+	}
+
+	if _, err := CompositeByWeight([]image.Image{red, blue}, []*image.Gray{wr}); err == nil {
+		t.Error("expected an error for a weight map count mismatch")
+	}
+	if _, err := CompositeByWeight([]image.Image{red, blue}, []*image.Gray{wr, image.NewGray(image.Rect(0, 0, 5, 4))}); err == nil {
+		t.Error("expected an error for a weight map size mismatch")
+	}
+	if _, err := CompositeByWeight([]image.Image{red, blue}, []*image.Gray{wr, nil}); err == nil {
+		t.Error("expected an error for a nil weight map")
+	}
+}
@@ -0,0 +1,119 @@
+// Package compose splits, crops and combines images: tiles, grids and stacks.
+//
+// The package works with the Image interface described in the image package.
+//
+// Functions return new images and never modify their inputs.
+package compose
+
+import (
+	"image"
+)
+
+// Tiles splits an image into a grid of tileW×tileH tiles, overlapping by overlap pixels.
+//
+// Tiles are returned in row-major order, as copies anchored at the origin.
+// The last row and column are moved back to fit inside the image, overlapping their neighbors by more,
+// so all tiles have the same size, unless the image is smaller than a tile.
+// The overlap must be smaller than the tile size;
+// along a side where the image is smaller than a tile, it is reduced to fit the image.
+func Tiles(src image.Image, tileW, tileH, overlap int) []image.Image {
+	if overlap >= tileW || overlap >= tileH {
+		panic("Invalid tile overlap")
+	}
+	bounds := src.Bounds()
+	tileW = min(tileW, bounds.Dx())
+	tileH = min(tileH, bounds.Dy())
+	xs := tilePositions(bounds.Dx(), tileW, min(overlap, tileW-1))
+	ys := tilePositions(bounds.Dy(), tileH, min(overlap, tileH-1))
+
+	res := make([]image.Image, 0, len(xs)*len(ys))
+	for _, y := range ys {
+		for _, x := range xs {
+			r := image.Rect(x, y, x+tileW, y+tileH).Add(bounds.Min)
+			res = append(res, crop(src, r))
+		}
+	}
+	return res
+}
+
+// Untile stitches tiles produced by Tiles back into an image with the given bounds,
+// where cols is the number of tiles per row.
+//
+// Overlapping tiles are blended, each one weighted down towards its edges.
+func Untile(tiles []image.Image, cols int, bounds image.Rectangle, overlap int) *image.RGBA {
+	dst := image.NewRGBA(bounds)
+	if len(tiles) == 0 {
+		return dst
+	}
+
+	// as in Tiles, the overlap is reduced for tiles clamped to the image
+	size := tiles[0].Bounds().Size()
+	tileW, tileH := min(size.X, bounds.Dx()), min(size.Y, bounds.Dy())
+	overlapX, overlapY := min(overlap, tileW-1), min(overlap, tileH-1)
+	xs := tilePositions(bounds.Dx(), tileW, overlapX)
+	ys := tilePositions(bounds.Dy(), tileH, overlapY)
+	if cols != len(xs) || len(tiles) != len(xs)*len(ys) {
+		panic("Invalid tile layout")
+	}
+
+	w, h := bounds.Dx(), bounds.Dy()
+	acc := make([]float64, 4*w*h)
+	sum := make([]float64, w*h)
+
+	for i, tile := range tiles {
+		tb := tile.Bounds()
+		x0, y0 := xs[i%cols], ys[i/cols]
+		for y := 0; y < tb.Dy(); y++ {
+			wy := tileWeight(y, tb.Dy(), overlapY)
+			for x := 0; x < tb.Dx(); x++ {
+				wt := tileWeight(x, tb.Dx(), overlapX) * wy
+				r, g, b, a := tile.At(tb.Min.X+x, tb.Min.Y+y).RGBA()
+
+				j := (y0+y)*w + x0 + x
+				acc[4*j+0] += wt * float64(r)
+				acc[4*j+1] += wt * float64(g)
+				acc[4*j+2] += wt * float64(b)
+				acc[4*j+3] += wt * float64(a)
+				sum[j] += wt
+			}
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			j := y*w + x
+			if sum[j] == 0 {
+				continue
+			}
+			i := y*dst.Stride + 4*x
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(acc[4*j+c]/sum[j]/0x101 + 0.5)
+			}
+		}
+	}
+	return dst
+}
+
+// tilePositions returns the offsets of tiles of the given size, overlapping by overlap,
+// along an axis of the given length.
+// The last tile is moved back to end at the axis length.
+func tilePositions(length, tile, overlap int) []int {
+	step := tile - overlap
+	if step <= 0 {
+		panic("Invalid tile overlap")
+	}
+
+	var res []int
+	for p := 0; ; p += step {
+		if p+tile >= length {
+			res = append(res, max(length-tile, 0))
+			return res
+		}
+		res = append(res, p)
+	}
+}
+
+// tileWeight ramps linearly up from each edge of a tile, across the overlap.
+func tileWeight(i, size, overlap int) float64 {
+	return float64(min(i+1, size-i, overlap+1))
+}
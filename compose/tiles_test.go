@@ -0,0 +1,92 @@
+package compose
+
+import (
+	"image"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func Test_Tiles(t *testing.T) {
+	img := image.NewRGBA(image.Rect(3, 5, 53, 35))
+	rand.New(rand.NewSource(42)).Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	tiles := Tiles(img, 16, 16, 0)
+	if len(tiles) != 4*2 {
+		t.Fatalf("expected: 8 tiles, got: %d", len(tiles))
+	}
+	for _, tile := range tiles {
+		if r := image.Rect(0, 0, 16, 16); tile.Bounds() != r {
+			t.Errorf("expected: %v, got: %v", r, tile.Bounds())
+		}
+	}
+
+	// last column moved back
+	if c, e := tiles[3].At(15, 15), img.At(52, 20); c != e {
+		t.Errorf("expected: %v, got: %v", e, c)
+	}
+
+	res := Untile(tiles, 4, img.Rect, 0)
+	if !reflect.DeepEqual(res, img) {
+		t.Error("untiled image doesn't match")
+	}
+
+	// overlapping tiles of a flat image stay flat
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	tiles = Tiles(img, 20, 12, 4)
+	res = Untile(tiles, 3, img.Rect, 4)
+	if !reflect.DeepEqual(res, img) {
+		t.Error("untiled overlapping image doesn't match")
+	}
+}
+
+func Test_TilesSmall(t *testing.T) {
+	// images smaller than a tile, along one or both sides
+	for _, tt := range []struct {
+		r     image.Rectangle
+		count int
+	}{
+		{image.Rect(0, 0, 5, 5), 1},
+		{image.Rect(2, 1, 7, 41), 4},
+	} {
+		r := tt.r
+		img := image.NewRGBA(r)
+		rand.New(rand.NewSource(7)).Read(img.Pix)
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+
+		tiles := Tiles(img, 16, 16, 8)
+		if len(tiles) != tt.count {
+			t.Fatalf("%v: expected: %d tiles, got: %d", r, tt.count, len(tiles))
+		}
+		if size := image.Pt(5, min(16, r.Dy())); tiles[0].Bounds().Size() != size {
+			t.Errorf("%v: expected: %v, got: %v", r, size, tiles[0].Bounds().Size())
+		}
+		if res := Untile(tiles, 1, r, 8); !reflect.DeepEqual(res, img) {
+			t.Errorf("%v: untiled image doesn't match", r)
+		}
+	}
+}
+
+func Test_tilePositions(t *testing.T) {
+	tests := []struct {
+		length, tile, overlap int
+		want                  []int
+	}{
+		{10, 5, 0, []int{0, 5}},
+		{11, 5, 0, []int{0, 5, 6}},
+		{10, 5, 2, []int{0, 3, 5}},
+		{3, 3, 1, []int{0}},
+	}
+	for _, tt := range tests {
+		if got := tilePositions(tt.length, tt.tile, tt.overlap); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tilePositions(%d, %d, %d) = %v, want %v", tt.length, tt.tile, tt.overlap, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"image"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// AutoWhiteBalance neutralizes a uniform color cast, under the gray-world assumption:
+// that the average color of a scene is gray.
+//
+// It is equivalent to AutoWhiteBalanceStrength with a strength of 1.
+func AutoWhiteBalance(img image.Image) *image.NRGBA {
+	return AutoWhiteBalanceStrength(img, 1)
+}
+
+// AutoWhiteBalanceStrength neutralizes a uniform color cast, under the gray-world assumption.
+//
+// The mean of each channel is computed in linear light (weighted by alpha),
+// and each channel is scaled so that its mean matches the mean of the three.
+// Strength, in [0, 1], partially applies the correction: 0 leaves the image unchanged.
+// Scaled values are clamped.
+func AutoWhiteBalanceStrength(img image.Image, strength float64) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	var sum [4]float64
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+4*w]
+		for i, v := range row {
+			sum[i%4] += float64(v)
+		}
+	}
+	gray := (sum[0] + sum[1] + sum[2]) / 3
+	if gray <= 0 {
+		return imageutil.FromLinearFloat(src)
+	}
+
+	strength = max(0, min(strength, 1))
+	var gain [4]float32
+	gain[3] = 1
+	for c := range 3 {
+		g := 1.0
+		if sum[c] > 0 {
+			g = gray / sum[c]
+		}
+		gain[c] = float32(1 + strength*(g-1))
+	}
+
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+4*w]
+		for i := range row {
+			row[i] *= gain[i%4]
+		}
+	}
+	return imageutil.FromLinearFloat(src)
+}
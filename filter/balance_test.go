@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+func Test_AutoWhiteBalance(t *testing.T) {
+	// a neutral scene, with a blue cast
+	img := image.NewNRGBA(image.Rect(2, 2, 34, 18))
+	for y := 2; y < 18; y++ {
+		for x := 2; x < 34; x++ {
+			v := uint8(4*x + 2*y)
+			img.SetNRGBA(x, y, color.NRGBA{v / 2, v / 2, v, 255})
+		}
+	}
+
+	// channel means, in linear light
+	means := func(img *image.NRGBA) (m [3]float64) {
+		for i := 0; i < len(img.Pix); i += 4 {
+			for c := range m {
+				m[c] += float64(imageutil.SRGB8ToLinear(img.Pix[i+c]))
+			}
+		}
+		return m
+	}
+	spread := func(m [3]float64) float64 {
+		return (max(m[0], m[1], m[2]) - min(m[0], m[1], m[2])) / (m[0] + m[1] + m[2])
+	}
+
+	dst := AutoWhiteBalance(img)
+	if dst.Rect != image.Rect(0, 0, 32, 16) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	if s := spread(means(dst)); s > 0.01 {
+		t.Errorf("expected balanced channels, got a spread of %f", s)
+	}
+	// red and green are boosted, blue is reduced
+	if c, o := dst.NRGBAAt(16, 8), img.NRGBAAt(18, 10); c.R <= o.R || c.G <= o.G || c.B >= o.B {
+		t.Errorf("unexpected color: %v, was %v", c, o)
+	}
+
+	// half strength is in between
+	before, half := spread(means(img)), spread(means(AutoWhiteBalanceStrength(img, 0.5)))
+	if !(0.01 < half && half < before) {
+		t.Errorf("expected a partial correction: %f, %f", before, half)
+	}
+
+	// zero strength is identity
+	if string(AutoWhiteBalanceStrength(img, 0).Pix) != string(toNRGBA(img).Pix) {
+		t.Error("strength=0 is not identity")
+	}
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Bilateral smooths an image while preserving edges, using a bilateral filter in linear light.
+//
+// Each pixel is replaced by an average of its neighbors, weighted by a spatial Gaussian
+// (with sigmaSpace in pixels) times a range Gaussian on the color difference
+// (with sigmaColor as a fraction of full scale, in linear light, e.g. 0.1).
+// The spatial kernel is truncated at 2*sigmaSpace pixels,
+// so the cost per pixel is quadratic in sigmaSpace.
+func Bilateral(img image.Image, sigmaSpace, sigmaColor float64) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	if sigmaSpace <= 0 || sigmaColor <= 0 {
+		return imageutil.FromLinearFloat(src)
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	radius := int(math.Ceil(2 * sigmaSpace))
+	spatial := make([]float32, (2*radius+1)*(2*radius+1))
+	for v := -radius; v <= radius; v++ {
+		for u := -radius; u <= radius; u++ {
+			spatial[(v+radius)*(2*radius+1)+u+radius] = float32(math.Exp(-float64(u*u+v*v) / (2 * sigmaSpace * sigmaSpace)))
+		}
+	}
+	rangeScale := float32(-1 / (2 * sigmaColor * sigmaColor))
+
+	dst := imageutil.NewLinearRGBA(src.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := src.Pix[y*src.Stride+4*x:]
+			var r, g, b, a, sum float32
+			for v := max(y-radius, 0); v <= min(y+radius, h-1); v++ {
+				for u := max(x-radius, 0); u <= min(x+radius, w-1); u++ {
+					p := src.Pix[v*src.Stride+4*u:]
+					dr, dg, db, da := p[0]-c[0], p[1]-c[1], p[2]-c[2], p[3]-c[3]
+					d2 := dr*dr + dg*dg + db*db + da*da
+					w := spatial[(v-y+radius)*(2*radius+1)+u-x+radius] *
+						float32(math.Exp(float64(d2*rangeScale)))
+					r += w * p[0]
+					g += w * p[1]
+					b += w * p[2]
+					a += w * p[3]
+					sum += w
+				}
+			}
+			d := dst.Pix[y*dst.Stride+4*x:]
+			d[0], d[1], d[2], d[3] = r/sum, g/sum, b/sum, a/sum
+		}
+	}
+	return imageutil.FromLinearFloat(dst)
+}
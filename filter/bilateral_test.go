@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_Bilateral(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			var v uint8
+			if x < 16 {
+				v = uint8(100 + rnd.Intn(11) - 5) // noisy flat region
+			} else {
+				v = 240
+			}
+			img.SetNRGBA(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+
+	dst := Bilateral(img, 2, 0.1)
+	if dst.Rect != img.Rect {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+
+	variance := func(img *image.NRGBA) float64 {
+		var sum, sqr float64
+		for y := 2; y < 14; y++ {
+			for x := 2; x < 14; x++ {
+				v := float64(img.NRGBAAt(x, y).R)
+				sum += v
+				sqr += v * v
+			}
+		}
+		n := 12.0 * 12
+		return sqr/n - sum*sum/n/n
+	}
+	if v0, v1 := variance(img), variance(dst); v1 > v0/4 {
+		t.Errorf("noise not smoothed, variance: %v, %v", v0, v1)
+	}
+
+	for y := 0; y < 16; y++ {
+		l := dst.NRGBAAt(15, y).R
+		r := dst.NRGBAAt(16, y).R
+		if l < 90 || l > 110 || r < 238 {
+			t.Errorf("at row %d, edge not preserved: %d, %d", y, l, r)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/internal/tiles"
+)
+
+// tileSize is the size of the tiles that heavy filters are split into, to run in parallel.
+const tileSize = 64
+
+// gaussianKernel returns a normalized Gaussian kernel, truncated at 3*sigma.
+func gaussianKernel(sigma float64) []float32 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float32, 2*radius+1)
+
+	var sum float64
+	for i := range kernel {
+		x := float64(i - radius)
+		w := math.Exp(-x * x / (2 * sigma * sigma))
+		kernel[i] = float32(w)
+		sum += w
+	}
+	for i := range kernel {
+		kernel[i] /= float32(sum)
+	}
+	return kernel
+}
+
+// gaussianBlur blurs interleaved float32 pixels with the given number of channels,
+// with a separable Gaussian of standard deviation sigma.
+// Edge pixels are extended.
+func gaussianBlur(pix []float32, w, h, channels int, sigma float64) []float32 {
+	if sigma <= 0 {
+		return append([]float32(nil), pix...)
+	}
+	return separable(pix, w, h, channels, gaussianKernel(sigma), Clamp)
+}
+
+// separable convolves interleaved float32 pixels with the given number of channels,
+// with kernel horizontally, then vertically, sampling beyond the edges with mode.
+func separable(pix []float32, w, h, channels int, kernel []float32, mode EdgeMode) []float32 {
+	radius := len(kernel) / 2
+
+	tmp := make([]float32, len(pix))
+	dst := make([]float32, len(pix))
+
+	// horizontal
+	tiles.Map(image.Rect(0, 0, w, h), tileSize, 0, func(r image.Rectangle) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				d := tmp[channels*(y*w+x):]
+				for k, f := range kernel {
+					sx, ok := mode.index(x+k-radius, w)
+					if !ok {
+						continue
+					}
+					s := pix[channels*(y*w+sx):]
+					for c := 0; c < channels; c++ {
+						d[c] += f * s[c]
+					}
+				}
+			}
+		}
+	})
+
+	// vertical
+	tiles.Map(image.Rect(0, 0, w, h), tileSize, 0, func(r image.Rectangle) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				d := dst[channels*(y*w+x):]
+				for k, f := range kernel {
+					sy, ok := mode.index(y+k-radius, h)
+					if !ok {
+						continue
+					}
+					s := tmp[channels*(sy*w+x):]
+					for c := 0; c < channels; c++ {
+						d[c] += f * s[c]
+					}
+				}
+			}
+		}
+	})
+
+	return dst
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_gaussianBlur(t *testing.T) {
+	// an impulse spreads into the kernel, preserving energy
+	const w, h = 21, 15
+	pix := make([]float32, 2*w*h)
+	pix[2*(7*w+10)] = 1
+	pix[2*(7*w+10)+1] = 0.5
+
+	res := gaussianBlur(pix, w, h, 2, 1.5)
+
+	var sum0, sum1 float64
+	for i := 0; i < len(res); i += 2 {
+		sum0 += float64(res[i])
+		sum1 += float64(res[i+1])
+	}
+	if math.Abs(sum0-1) > 1e-5 || math.Abs(sum1-0.5) > 1e-5 {
+		t.Errorf("energy not preserved: %v %v", sum0, sum1)
+	}
+
+	kernel := gaussianKernel(1.5)
+	r := len(kernel) / 2
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			exp := kernel[dy+r] * kernel[dx+r]
+			got := res[2*((7+dy)*w+10+dx)]
+			if math.Abs(float64(exp-got)) > 1e-6 {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", dx, dy, exp, got)
+			}
+		}
+	}
+	if res[0] != 0 {
+		t.Errorf("unexpected spread: %v", res[0])
+	}
+}
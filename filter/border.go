@@ -0,0 +1,114 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Border draws a solid border of color c around an image.
+//
+// A positive width outsets the border around the image, expanding the canvas by width pixels on each side.
+// A negative width insets the border into the image, over its outermost -width pixels.
+// If c is translucent, it's composited over the image, in linear light.
+func Border(img image.Image, width int, c color.Color) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	if width == 0 {
+		return imageutil.FromLinearFloat(src)
+	}
+
+	dst := src
+	if width > 0 {
+		dst = imageutil.NewLinearRGBA(image.Rect(0, 0, src.Rect.Dx()+2*width, src.Rect.Dy()+2*width))
+		for y := 0; y < src.Rect.Dy(); y++ {
+			copy(dst.Pix[(y+width)*dst.Stride+4*width:], src.Pix[y*src.Stride:y*src.Stride+4*src.Rect.Dx()])
+		}
+	} else {
+		width = -width
+	}
+
+	// border color, premultiplied, in linear light
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	a := float32(n.A) / 65535
+	bc := [4]float32{
+		a * float32(imageutil.SRGB16ToLinear(n.R)) / 65535,
+		a * float32(imageutil.SRGB16ToLinear(n.G)) / 65535,
+		a * float32(imageutil.SRGB16ToLinear(n.B)) / 65535,
+		a,
+	}
+
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+	inner := image.Rect(width, width, w-width, h-width)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if (image.Point{x, y}).In(inner) {
+				continue
+			}
+			d := dst.Pix[y*dst.Stride+4*x:]
+			for i := range bc {
+				d[i] = bc[i] + (1-a)*d[i]
+			}
+		}
+	}
+	return imageutil.FromLinearFloat(dst)
+}
+
+// ReflectPad surrounds an image with pad pixels of border on each side,
+// mirrored from the image as by the Reflect EdgeMode,
+// so convolutions can process the result without special-casing edges.
+// Use Unpad to crop the border back.
+// The result is anchored at the origin.
+func ReflectPad(img image.Image, pad int) *image.NRGBA {
+	if pad < 0 {
+		panic("Invalid padding")
+	}
+	return CropExtend(img, img.Bounds().Inset(-pad), Reflect)
+}
+
+// CropExtend crops the rectangle r (in the coordinates of img) out of an image.
+// Where r extends beyond the image, pixels are sampled according to mode, instead of clipped;
+// Zero fills them with transparent black.
+// The result has the size of r, and is anchored at the origin.
+func CropExtend(img image.Image, r image.Rectangle, mode EdgeMode) *image.NRGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	if w <= 0 || h <= 0 {
+		return dst
+	}
+	src := toNRGBA(img)
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		sy, oky := mode.index(r.Min.Y-bounds.Min.Y+y, h)
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			sx, okx := mode.index(r.Min.X-bounds.Min.X+x, w)
+			if okx && oky {
+				copy(dst.Pix[y*dst.Stride+4*x:][:4], src.Pix[sy*src.Stride+4*sx:])
+			}
+		}
+	}
+	return dst
+}
+
+// CropExtendColor crops the rectangle r (in the coordinates of img) out of an image,
+// like CropExtend, filling where r extends beyond the image with color c.
+func CropExtendColor(img image.Image, r image.Rectangle, c color.Color) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, r.Min, draw.Src)
+	return dst
+}
+
+// Unpad crops pad pixels of border off each side of an image, undoing ReflectPad.
+// The result is a copy anchored at the origin.
+func Unpad(img image.Image, pad int) *image.NRGBA {
+	if pad < 0 {
+		panic("Invalid padding")
+	}
+	r := img.Bounds().Inset(pad)
+	dst := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Rect, img, r.Min, draw.Src)
+	return dst
+}
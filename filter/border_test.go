@@ -0,0 +1,154 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Border(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 25, 15))
+	fillRandom(img.Pix, testSeed(t))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	red := color.NRGBA{255, 0, 0, 255}
+
+	// outset
+	dst := Border(img, 3, red)
+	if r := image.Rect(0, 0, 26, 16); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 26; x++ {
+			c := dst.NRGBAAt(x, y)
+			if x < 3 || y < 3 || x >= 23 || y >= 13 {
+				if c != red {
+					t.Fatalf("at %dx%d, expected border, got: %v", x, y, c)
+				}
+			} else if e := img.NRGBAAt(x+2, y+2); c != e {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, e, c)
+			}
+		}
+	}
+
+	// inset, translucent
+	dst = Border(img, -2, color.NRGBA{0, 0, 0, 128})
+	if r := image.Rect(0, 0, 20, 10); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			c := dst.NRGBAAt(x, y)
+			e := img.NRGBAAt(x+5, y+5)
+			if x < 2 || y < 2 || x >= 18 || y >= 8 {
+				// darkened by half, in linear light
+				if exp := scaleLinear(e.R, 1-128.0/255); abs(int(c.R)-int(exp)) > 1 || c.A != 255 {
+					t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, exp, c)
+				}
+			} else if c != e {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, e, c)
+			}
+		}
+	}
+}
+
+func Test_ReflectPad(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 12, 10))
+	fillRandom(img.Pix, testSeed(t))
+	w, h := 7, 5
+
+	const pad = 3
+	dst := ReflectPad(img, pad)
+	if r := image.Rect(0, 0, w+2*pad, h+2*pad); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+
+	// the rows above the image mirror the rows inside it, repeating the edge
+	for i := 0; i < pad; i++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			if dst.NRGBAAt(x, pad-1-i) != dst.NRGBAAt(x, pad+i) {
+				t.Fatalf("row %d doesn't mirror row %d", pad-1-i, pad+i)
+			}
+			if dst.NRGBAAt(x, pad+h+i) != dst.NRGBAAt(x, pad+h-1-i) {
+				t.Fatalf("row %d doesn't mirror row %d", pad+h+i, pad+h-1-i)
+			}
+		}
+	}
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for i := 0; i < pad; i++ {
+			if dst.NRGBAAt(pad-1-i, y) != dst.NRGBAAt(pad+i, y) {
+				t.Fatalf("column %d doesn't mirror column %d", pad-1-i, pad+i)
+			}
+		}
+	}
+
+	// the padding is larger than the image: -12 reflects to 2 (of 7), and 1 (of 5)
+	if dst := ReflectPad(img, 12); dst.NRGBAAt(0, 0) != img.NRGBAAt(7, 6) {
+		t.Errorf("expected: %v, got: %v", img.NRGBAAt(7, 6), dst.NRGBAAt(0, 0))
+	}
+
+	res := Unpad(dst, pad)
+	if res.Rect != image.Rect(0, 0, w, h) {
+		t.Fatalf("unexpected bounds: %v", res.Rect)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if res.NRGBAAt(x, y) != img.NRGBAAt(5+x, 5+y) {
+				t.Fatalf("at %dx%d, colors don't match", x, y)
+			}
+		}
+	}
+}
+
+func Test_CropExtend(t *testing.T) {
+	// a 4×2 image, with distinct columns: a, b, c, d
+	img := image.NewNRGBA(image.Rect(10, 10, 14, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 14; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	col := func(x int) color.NRGBA { return color.NRGBA{uint8(10 + x), 10, 0, 255} }
+
+	// extends 3 pixels past the right edge: abcd|???
+	r := image.Rect(10, 10, 17, 11)
+	tests := []struct {
+		mode EdgeMode
+		want []color.NRGBA
+	}{
+		{Clamp, []color.NRGBA{col(3), col(3), col(3)}},
+		{Reflect, []color.NRGBA{col(3), col(2), col(1)}},
+		{Wrap, []color.NRGBA{col(0), col(1), col(2)}},
+		{Zero, []color.NRGBA{{}, {}, {}}},
+	}
+	for _, tt := range tests {
+		dst := CropExtend(img, r, tt.mode)
+		if dst.Rect != image.Rect(0, 0, 7, 1) {
+			t.Fatalf("mode %d: unexpected bounds: %v", tt.mode, dst.Rect)
+		}
+		for x := 0; x < 7; x++ {
+			want := col(x)
+			if x >= 4 {
+				want = tt.want[x-4]
+			}
+			if c := dst.NRGBAAt(x, 0); c != want {
+				t.Errorf("mode %d, at %d: expected: %v, got: %v", tt.mode, x, want, c)
+			}
+		}
+	}
+
+	red := color.NRGBA{255, 0, 0, 255}
+	dst := CropExtendColor(img, image.Rect(8, 11, 12, 13), red)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			want := red
+			if x >= 2 && y == 0 {
+				want = img.NRGBAAt(8+x, 11+y)
+			}
+			if c := dst.NRGBAAt(x, y); c != want {
+				t.Errorf("at %dx%d: expected: %v, got: %v", x, y, want, c)
+			}
+		}
+	}
+}
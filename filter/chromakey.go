@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// ChromaKey removes a key color (e.g. a green screen) from an image, setting alpha
+// based on the distance of each pixel from the key color, in the CbCr chroma plane.
+//
+// Distances are in 8-bit CbCr units. Pixels within tolerance of the key become transparent;
+// pixels beyond tolerance+softness are kept; alpha ramps smoothly in between.
+// Luma is ignored, so uneven lighting of the screen is tolerated.
+//
+// In the transition band, where semi-transparent edges pick up light reflected by the screen,
+// color spill is suppressed by limiting the key's dominant channel to the other two.
+func ChromaKey(img image.Image, key color.Color, tolerance, softness float64) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	k := color.NRGBAModel.Convert(key).(color.NRGBA)
+	_, kcb, kcr := color.RGBToYCbCr(k.R, k.G, k.B)
+
+	dominant := 0
+	if k.G > k.R && k.G >= k.B {
+		dominant = 1
+	} else if k.B > k.R && k.B > k.G {
+		dominant = 2
+	}
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+4 : i+4]
+			i += 4
+
+			_, cb, cr := color.RGBToYCbCr(p[0], p[1], p[2])
+			d := math.Hypot(float64(cb)-float64(kcb), float64(cr)-float64(kcr))
+
+			var f float64
+			switch {
+			case d <= tolerance:
+				f = 0
+			case d >= tolerance+softness:
+				continue
+			default:
+				t := (d - tolerance) / softness
+				f = t * t * (3 - 2*t)
+			}
+
+			p[3] = uint8(math.Floor(float64(p[3])*f + 0.5))
+
+			// spill suppression
+			o1, o2 := p[(dominant+1)%3], p[(dominant+2)%3]
+			if o1 < o2 {
+				o1 = o2
+			}
+			if p[dominant] > o1 {
+				p[dominant] = o1
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,32 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ChromaKey(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 5, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 255, 0, 255})     // key
+	img.SetNRGBA(1, 0, color.NRGBA{0, 230, 0, 255})     // key, darker
+	img.SetNRGBA(2, 0, color.NRGBA{200, 40, 40, 255})   // foreground
+	img.SetNRGBA(3, 0, color.NRGBA{230, 200, 180, 255}) // foreground
+	img.SetNRGBA(4, 0, color.NRGBA{80, 220, 80, 255})   // edge, with spill
+
+	dst := ChromaKey(img, color.NRGBA{0, 255, 0, 255}, 40, 60)
+
+	for _, x := range []int{0, 1} {
+		if a := dst.NRGBAAt(x, 0).A; a != 0 {
+			t.Errorf("at %d, key not transparent: %d", x, a)
+		}
+	}
+	for _, x := range []int{2, 3} {
+		if exp, res := img.NRGBAAt(x, 0), dst.NRGBAAt(x, 0); exp != res {
+			t.Errorf("at %d, expected: %v, got: %v", x, exp, res)
+		}
+	}
+	if res := dst.NRGBAAt(4, 0); res.A == 0 || res.A == 255 || res.G > res.R {
+		t.Errorf("edge, unexpected: %v", res)
+	}
+}
@@ -0,0 +1,166 @@
+package filter
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+	"github.com/ncruces/go-image/internal/tiles"
+)
+
+// EdgeMode specifies how convolutions sample pixels beyond the edges of an image.
+type EdgeMode int
+
+const (
+	// Clamp extends the edge pixels: aaa|abcd|ddd.
+	Clamp EdgeMode = iota
+	// Reflect mirrors the image, repeating the edge pixels: cba|abcd|dcb.
+	Reflect
+	// Wrap tiles the image: bcd|abcd|abc.
+	Wrap
+	// Zero treats pixels beyond the edges as transparent black (black, for grayscale).
+	Zero
+)
+
+// index maps coordinate i, of a dimension of size n, to a coordinate inside [0, n[.
+// It returns false if the sample is zero.
+func (mode EdgeMode) index(i, n int) (int, bool) {
+	if 0 <= i && i < n {
+		return i, true
+	}
+	switch mode {
+	case Clamp:
+		return max(0, min(i, n-1)), true
+	case Reflect:
+		i = ((i % (2 * n)) + 2*n) % (2 * n)
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return i, true
+	case Wrap:
+		return ((i % n) + n) % n, true
+	case Zero:
+		return 0, false
+	}
+	panic("Unknown edge mode")
+}
+
+// Convolve convolves an image with a kernel, given as rows of weights, both of odd length.
+//
+// The kernel is applied in linear light, with premultiplied alpha, as is:
+// it should be normalized to preserve brightness.
+// Pixels beyond the edges are sampled according to mode.
+func Convolve(img image.Image, kernel [][]float64, mode EdgeMode) *image.NRGBA {
+	kh := len(kernel)
+	if kh%2 == 0 {
+		panic("Invalid kernel size")
+	}
+	kw := len(kernel[0])
+	for _, row := range kernel {
+		if len(row) != kw || kw%2 == 0 {
+			panic("Invalid kernel size")
+		}
+	}
+
+	src := imageutil.ToLinearFloat(img)
+	src.Pix = convolve(src.Pix, src.Rect.Dx(), src.Rect.Dy(), kernel, mode)
+	return imageutil.FromLinearFloat(src)
+}
+
+// convolve convolves w×h pixels of 4 float32 channels with a kernel of odd size.
+func convolve(pix []float32, w, h int, kernel [][]float64, mode EdgeMode) []float32 {
+	kh, kw := len(kernel), len(kernel[0])
+	dst := make([]float32, len(pix))
+
+	tiles.Map(image.Rect(0, 0, w, h), tileSize, 0, func(r image.Rectangle) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				var sum [4]float64
+				for ky, row := range kernel {
+					sy, ok := mode.index(y+ky-kh/2, h)
+					if !ok {
+						continue
+					}
+					for kx, f := range row {
+						sx, ok := mode.index(x+kx-kw/2, w)
+						if !ok {
+							continue
+						}
+						s := pix[4*(sy*w+sx):]
+						for c := range sum {
+							sum[c] += f * float64(s[c])
+						}
+					}
+				}
+				d := dst[4*(y*w+x):]
+				for c := range sum {
+					d[c] = float32(sum[c])
+				}
+			}
+		}
+	})
+	return dst
+}
+
+// GaussianBlur blurs an image with a Gaussian of standard deviation sigma, in linear light.
+// Pixels beyond the edges are sampled according to mode.
+func GaussianBlur(img image.Image, sigma float64, mode EdgeMode) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	if sigma <= 0 {
+		return imageutil.FromLinearFloat(src)
+	}
+	src.Pix = separable(src.Pix, src.Rect.Dx(), src.Rect.Dy(), 4, gaussianKernel(sigma), mode)
+	return imageutil.FromLinearFloat(src)
+}
+
+// BoxBlur replaces each pixel of an image with the average of its (2·radius+1)² neighborhood, in linear light.
+// Pixels beyond the edges are sampled according to mode.
+func BoxBlur(img image.Image, radius int, mode EdgeMode) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	if radius <= 0 {
+		return imageutil.FromLinearFloat(src)
+	}
+	kernel := make([]float32, 2*radius+1)
+	for i := range kernel {
+		kernel[i] = 1 / float32(len(kernel))
+	}
+	src.Pix = separable(src.Pix, src.Rect.Dx(), src.Rect.Dy(), 4, kernel, mode)
+	return imageutil.FromLinearFloat(src)
+}
+
+// Sobel computes the gradient magnitude of the grayscale version of an image, with the Sobel operator.
+//
+// The magnitude is divided by 4, so a step between two flat regions measures the step's height,
+// and clamped to 255.
+// Pixels beyond the edges are sampled according to mode.
+func Sobel(img image.Image, mode EdgeMode) *image.Gray {
+	bounds := img.Bounds()
+	src, ok := img.(*image.Gray)
+	if !ok || src.Rect.Min != (image.Point{}) {
+		src = image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+
+	at := func(x, y int) float64 {
+		sx, okx := mode.index(x, w)
+		sy, oky := mode.index(y, h)
+		if !okx || !oky {
+			return 0
+		}
+		return float64(src.Pix[sy*src.Stride+sx])
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			dst.Pix[y*dst.Stride+x] = uint8(math.Min(math.Hypot(gx, gy)/4, 255) + 0.5)
+		}
+	}
+	return dst
+}
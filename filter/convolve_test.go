@@ -0,0 +1,169 @@
+package filter
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+func Test_EdgeMode(t *testing.T) {
+	tests := []struct {
+		mode EdgeMode
+		want []int // indices for -5..8, of a dimension of size 4
+	}{
+		{Clamp, []int{0, 0, 0, 0, 0, 0, 1, 2, 3, 3, 3, 3, 3, 3}},
+		{Reflect, []int{3, 3, 2, 1, 0, 0, 1, 2, 3, 3, 2, 1, 0, 0}},
+		{Wrap, []int{3, 0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3, 0}},
+	}
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got, ok := tt.mode.index(i-5, 4); got != want || !ok {
+				t.Errorf("mode %d: index(%d) = %d, want %d", tt.mode, i-5, got, want)
+			}
+		}
+	}
+	for i := -5; i < 9; i++ {
+		if _, ok := Zero.index(i, 4); ok != (0 <= i && i < 4) {
+			t.Errorf("mode Zero: index(%d) = %v", i, ok)
+		}
+	}
+}
+
+func Test_BoxBlur(t *testing.T) {
+	// a white pixel, at the left edge of a black row
+	img := image.NewGray(image.Rect(0, 0, 4, 1))
+	img.Pix[0] = 255
+
+	srgb := func(v float64) uint8 { return imageutil.LinearToSRGB8(uint16(math.Round(65535 * v))) }
+
+	// the 5 samples around x=0 are: clamp aa|ab c, reflect ba|ab c, wrap dc|ab c, zero 00|ab c
+	tests := []struct {
+		mode EdgeMode
+		want uint8
+	}{
+		{Clamp, srgb(3.0 / 5)},
+		{Reflect, srgb(2.0 / 5)},
+		{Wrap, srgb(1.0 / 5)},
+	}
+	for _, tt := range tests {
+		dst := BoxBlur(img, 2, tt.mode)
+		if c := dst.NRGBAAt(0, 0); c.R != tt.want || c.A != 255 {
+			t.Errorf("mode %d: expected %d, got %v", tt.mode, tt.want, c)
+		}
+	}
+
+	// with zeros, the blur also fades alpha, vertically too: 1/5 × 3/5
+	dst := BoxBlur(img, 2, Zero)
+	if c := dst.NRGBAAt(0, 0); c.R != srgb(1.0/3) || c.A != 31 {
+		t.Errorf("mode Zero: expected %d/%d, got %v", srgb(1.0/3), 31, c)
+	}
+}
+
+func Test_Sobel(t *testing.T) {
+	// a horizontal ramp: 0, 10, 20, 30
+	img := image.NewGray(image.Rect(1, 1, 5, 5))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Pix[y*img.Stride+x] = uint8(10 * x)
+		}
+	}
+
+	tests := []struct {
+		mode EdgeMode
+		want uint8
+	}{
+		{Clamp, 10},   // gx = 4·10 - 4·0
+		{Reflect, 10}, // same as Clamp, one pixel away
+		{Wrap, 20},    // gx = 4·10 - 4·30
+		{Zero, 8},     // gx = 3·10 - 0, gy = 10 - 0: √1000 / 4
+	}
+	for _, tt := range tests {
+		dst := Sobel(img, tt.mode)
+		if dst.Rect != image.Rect(0, 0, 4, 4) {
+			t.Fatalf("unexpected bounds: %v", dst.Rect)
+		}
+		if v := dst.Pix[0]; v != tt.want {
+			t.Errorf("mode %d: expected %d, got %d", tt.mode, tt.want, v)
+		}
+		// inside, the gradient is twice the ramp's slope
+		if v := dst.Pix[1*dst.Stride+1]; v != 20 {
+			t.Errorf("mode %d: expected 20, got %d", tt.mode, v)
+		}
+	}
+}
+
+func Test_Convolve(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(2, 2, 10, 7))
+	fillRandom(img.Pix, testSeed(t))
+
+	// identity
+	dst := Convolve(img, [][]float64{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}}, Clamp)
+	ref := imageutil.FromLinearFloat(imageutil.ToLinearFloat(img))
+	if string(dst.Pix) != string(ref.Pix) {
+		t.Error("identity kernel changed the image")
+	}
+
+	// shift right by one, with zeros coming in from the left
+	dst = Convolve(img, [][]float64{{1, 0, 0}}, Zero)
+	for y := 0; y < 5; y++ {
+		if c := dst.NRGBAAt(0, y); c.A != 0 {
+			t.Errorf("expected transparent, got %v", c)
+		}
+		for x := 1; x < 8; x++ {
+			if dst.NRGBAAt(x, y) != ref.NRGBAAt(x-1, y) {
+				t.Fatalf("at %dx%d: expected %v, got %v", x, y, ref.NRGBAAt(x-1, y), dst.NRGBAAt(x, y))
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an even kernel")
+		}
+	}()
+	Convolve(img, [][]float64{{1, 1}}, Clamp)
+}
+
+func Test_GaussianBlur(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 9, 9))
+	img.Pix[0] = 255
+
+	// wrapping brings the corner's light to the opposite corner
+	if c := GaussianBlur(img, 1, Wrap).NRGBAAt(8, 8); c.R == 0 {
+		t.Errorf("expected light to wrap around, got %v", c)
+	}
+	if c := GaussianBlur(img, 1, Clamp).NRGBAAt(8, 8); c.R != 0 {
+		t.Errorf("expected no light, got %v", c)
+	}
+	if c := GaussianBlur(img, 0, Zero).NRGBAAt(0, 0); c.R != 255 {
+		t.Errorf("expected sigma=0 to be identity, got %v", c)
+	}
+}
+
+func Test_parallel(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 150, 100))
+	fillRandom(img.Pix, testSeed(t))
+	kernel := [][]float64{
+		{0, -1, 0},
+		{-1, 5, -1},
+		{0, -1, 0},
+	}
+
+	// the same filters run serially, and in parallel
+	procs := runtime.GOMAXPROCS(1)
+	blur := GaussianBlur(img, 2, Reflect)
+	conv := Convolve(img, kernel, Wrap)
+	runtime.GOMAXPROCS(max(procs, 4))
+	defer runtime.GOMAXPROCS(procs)
+
+	if got := GaussianBlur(img, 2, Reflect); !bytes.Equal(got.Pix, blur.Pix) {
+		t.Error("parallel GaussianBlur doesn't match serial")
+	}
+	if got := Convolve(img, kernel, Wrap); !bytes.Equal(got.Pix, conv.Pix) {
+		t.Error("parallel Convolve doesn't match serial")
+	}
+}
@@ -0,0 +1,198 @@
+package filter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LUT3D is a 3D color lookup table, such as a film or video color grade,
+// optionally preceded by a 1D shaper LUT.
+type LUT3D struct {
+	size      int
+	table     [][3]float64 // red varies fastest
+	min, max  [3]float64
+	shaper    [][3]float64
+	shaperMin [3]float64
+	shaperMax [3]float64
+}
+
+// LoadCubeLUT parses a LUT in the Adobe/Resolve .cube format.
+//
+// The file may have a 3D table (LUT_3D_SIZE), a 1D table (LUT_1D_SIZE), or both,
+// in which case the 1D table precedes the 3D table, and is applied first, as a shaper.
+// DOMAIN_MIN/DOMAIN_MAX and LUT_1D_INPUT_RANGE/LUT_3D_INPUT_RANGE are supported.
+func LoadCubeLUT(r io.Reader) (*LUT3D, error) {
+	lut := &LUT3D{
+		max:       [3]float64{1, 1, 1},
+		shaperMax: [3]float64{1, 1, 1},
+	}
+	var size1D int
+	var data [][3]float64
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		floats := func(n int) ([]float64, error) {
+			if len(fields) != n {
+				return nil, fmt.Errorf("filter: invalid .cube line %d", line)
+			}
+			res := make([]float64, n)
+			for i, f := range fields[len(fields)-n:] {
+				v, err := strconv.ParseFloat(f, 64)
+				if err != nil {
+					return nil, fmt.Errorf("filter: invalid .cube line %d", line)
+				}
+				res[i] = v
+			}
+			return res, nil
+		}
+		keyword := func(n int) ([]float64, error) {
+			fields = fields[1:]
+			return floats(n)
+		}
+
+		var err error
+		var v []float64
+		switch fields[0] {
+		case "TITLE":
+			continue
+		case "LUT_1D_SIZE":
+			if v, err = keyword(1); err == nil {
+				size1D = int(v[0])
+			}
+		case "LUT_3D_SIZE":
+			if v, err = keyword(1); err == nil {
+				lut.size = int(v[0])
+			}
+		case "DOMAIN_MIN":
+			if v, err = keyword(3); err == nil {
+				copy(lut.min[:], v)
+				copy(lut.shaperMin[:], v)
+			}
+		case "DOMAIN_MAX":
+			if v, err = keyword(3); err == nil {
+				copy(lut.max[:], v)
+				copy(lut.shaperMax[:], v)
+			}
+		case "LUT_1D_INPUT_RANGE":
+			if v, err = keyword(2); err == nil {
+				lut.shaperMin = [3]float64{v[0], v[0], v[0]}
+				lut.shaperMax = [3]float64{v[1], v[1], v[1]}
+			}
+		case "LUT_3D_INPUT_RANGE":
+			if v, err = keyword(2); err == nil {
+				lut.min = [3]float64{v[0], v[0], v[0]}
+				lut.max = [3]float64{v[1], v[1], v[1]}
+			}
+		default:
+			if v, err = floats(3); err == nil {
+				data = append(data, [3]float64{v[0], v[1], v[2]})
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if size1D == 1 || lut.size == 1 || size1D > 65536 || lut.size > 256 || size1D < 0 || lut.size < 0 {
+		return nil, errors.New("filter: invalid .cube LUT size")
+	}
+	if size1D == 0 && lut.size == 0 {
+		return nil, errors.New("filter: missing .cube LUT size")
+	}
+	if len(data) != size1D+lut.size*lut.size*lut.size {
+		return nil, errors.New("filter: wrong number of .cube LUT entries")
+	}
+	lut.shaper = data[:size1D:size1D]
+	lut.table = data[size1D:]
+	return lut, nil
+}
+
+// Apply grades an image with the LUT, using trilinear interpolation.
+//
+// The LUT is applied to sRGB encoded values in [0, 1], which is the working space of most grades.
+// Alpha is preserved.
+func (l *LUT3D) Apply(img image.Image) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+3 : i+3]
+			c := l.lookup([3]float64{float64(p[0]) / 255, float64(p[1]) / 255, float64(p[2]) / 255})
+			p[0], p[1], p[2] = unit8(c[0]), unit8(c[1]), unit8(c[2])
+			i += 4
+		}
+	}
+	return dst
+}
+
+func (l *LUT3D) lookup(c [3]float64) [3]float64 {
+	if n := len(l.shaper); n > 0 {
+		for i := range c {
+			t := cubeIndex(c[i], l.shaperMin[i], l.shaperMax[i], n)
+			j := min(int(t), n-2)
+			f := t - float64(j)
+			c[i] = l.shaper[j][i]*(1-f) + l.shaper[j+1][i]*f
+		}
+	}
+	if l.size == 0 {
+		return c
+	}
+
+	n := l.size
+	var idx [3]int
+	var frac [3]float64
+	for i := range c {
+		t := cubeIndex(c[i], l.min[i], l.max[i], n)
+		idx[i] = min(int(t), n-2)
+		frac[i] = t - float64(idx[i])
+	}
+
+	var res [3]float64
+	for corner := range 8 {
+		w := 1.0
+		at := 0
+		stride := 1
+		for i := range 3 {
+			j := idx[i]
+			if corner>>i&1 != 0 {
+				j++
+				w *= frac[i]
+			} else {
+				w *= 1 - frac[i]
+			}
+			at += j * stride
+			stride *= n
+		}
+		if w == 0 {
+			continue
+		}
+		for i := range res {
+			res[i] += w * l.table[at][i]
+		}
+	}
+	return res
+}
+
+// cubeIndex maps a value in [min, max] to a fractional index into a table of n entries, clamped.
+func cubeIndex(v, min, max float64, n int) float64 {
+	t := (v - min) / (max - min) * float64(n-1)
+	if !(t > 0) {
+		return 0
+	}
+	return math.Min(t, float64(n-1))
+}
@@ -0,0 +1,31 @@
+package filter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ExportToneLUT writes a tone curve, such as those used with Curves, as a 1D LUT in the .cube format,
+// sampled at size evenly spaced points, so it can be reused in other tools.
+//
+// The curve is applied equally to all channels, and its output is clamped to [0, 1].
+// A nil curve is the identity. Size must be between 2 and 65536.
+func ExportToneLUT(curve func(float64) float64, size int, w io.Writer) error {
+	if size < 2 || size > 65536 {
+		return errors.New("filter: invalid LUT size")
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "LUT_1D_SIZE %d\n", size)
+	for i := 0; i < size; i++ {
+		v := float64(i) / float64(size-1)
+		if curve != nil {
+			v = math.Max(0, math.Min(curve(v), 1))
+		}
+		fmt.Fprintf(bw, "%.6f %.6f %.6f\n", v, v, v)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,51 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+)
+
+func Test_ExportToneLUT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportToneLUT(nil, 5, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || lines[0] != "LUT_1D_SIZE 5" {
+		t.Fatalf("unexpected header: %q", lines)
+	}
+	for i, l := range lines[1:] {
+		v := float64(i) / 4
+		if exp := fmt.Sprintf("%.6f %.6f %.6f", v, v, v); l != exp {
+			t.Errorf("expected: %q, got: %q", exp, l)
+		}
+	}
+
+	// round trip a curve
+	curve := SplineCurve([][2]float64{{0, 0.1}, {0.5, 0.7}, {1, 0.9}})
+	buf.Reset()
+	if err := ExportToneLUT(curve, 256, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lut, err := LoadCubeLUT(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewGray(image.Rect(0, 0, 5, 1))
+	copy(img.Pix, []uint8{0, 64, 128, 192, 255})
+	a, b := lut.Apply(img), Curves(img, curve, curve, curve)
+	for i, p := range a.Pix {
+		if abs(int(p)-int(b.Pix[i])) > 1 {
+			t.Errorf("round trip doesn't match: %v, %v", a.Pix, b.Pix)
+			break
+		}
+	}
+
+	if err := ExportToneLUT(nil, 1, &buf); err == nil {
+		t.Error("expected an error")
+	}
+}
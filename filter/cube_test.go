@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func Test_LoadCubeLUT(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	fillRandom(img.Pix, testSeed(t))
+
+	cube := func(size int, f func(r, g, b float64) (float64, float64, float64)) string {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# comment\nTITLE \"test\"\nLUT_3D_SIZE %d\n\n", size)
+		for b := 0; b < size; b++ {
+			for g := 0; g < size; g++ {
+				for r := 0; r < size; r++ {
+					s := float64(size - 1)
+					x, y, z := f(float64(r)/s, float64(g)/s, float64(b)/s)
+					fmt.Fprintf(&sb, "%g %g %g\n", x, y, z)
+				}
+			}
+		}
+		return sb.String()
+	}
+
+	// identity
+	for _, size := range []int{2, 5, 33} {
+		lut, err := LoadCubeLUT(strings.NewReader(cube(size, func(r, g, b float64) (float64, float64, float64) {
+			return r, g, b
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst := lut.Apply(img); string(dst.Pix) != string(img.Pix) {
+			t.Errorf("size %d: identity LUT is not a no-op", size)
+		}
+	}
+
+	// a known swatch
+	lut, err := LoadCubeLUT(strings.NewReader(cube(3, func(r, g, b float64) (float64, float64, float64) {
+		return 1 - r, 1 - g, 1 - b
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	swatch := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	swatch.SetNRGBA(0, 0, color.NRGBA{10, 200, 30, 128})
+	if c := lut.Apply(swatch).NRGBAAt(0, 0); c != (color.NRGBA{245, 55, 225, 128}) {
+		t.Errorf("unexpected swatch: %v", c)
+	}
+
+	// a shaper
+	shaper := "LUT_1D_SIZE 3\nLUT_1D_INPUT_RANGE 0 1\n0 0 0\n0.25 0.5 0.5\n1 1 1\n" +
+		cube(2, func(r, g, b float64) (float64, float64, float64) { return r, g, b })
+	if lut, err = LoadCubeLUT(strings.NewReader(shaper)); err != nil {
+		t.Fatal(err)
+	}
+	swatch.SetNRGBA(0, 0, color.NRGBA{102, 102, 255, 255})
+	if c := lut.Apply(swatch).NRGBAAt(0, 0); c != (color.NRGBA{51, 102, 255, 255}) {
+		t.Errorf("unexpected shaped swatch: %v", c)
+	}
+
+	// errors
+	for _, s := range []string{
+		"",
+		"LUT_3D_SIZE 2\n0 0 0\n",
+		"LUT_3D_SIZE x\n",
+		"LUT_3D_SIZE 1\n0 0 0\n",
+		"LUT_1D_SIZE 2\n0 0\n1 1 1\n",
+	} {
+		if _, err := LoadCubeLUT(strings.NewReader(s)); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Curves applies tone curves to the red, green and blue channels of an image.
+//
+// Curves work on sRGB encoded values (not linear light), normalized to [0, 1],
+// as in most photo editors. Results are clamped to [0, 1].
+// A nil curve leaves the channel unchanged.
+func Curves(img image.Image, r, g, b func(float64) float64) *image.NRGBA {
+	dst := toNRGBA(img)
+	applyLUT(dst, curveLUT(r), curveLUT(g), curveLUT(b))
+	return dst
+}
+
+// SplineCurve builds a smooth, monotone, curve through a set of control points,
+// using monotone cubic Hermite interpolation (Fritsch–Carlson).
+//
+// The curve is constant outside the range of the control points,
+// and the identity if there are no control points.
+// Between increasing (or decreasing) control points the curve does not overshoot.
+func SplineCurve(points [][2]float64) func(float64) float64 {
+	pts := append([][2]float64(nil), points...)
+	sort.Slice(pts, func(i, j int) bool { return pts[i][0] < pts[j][0] })
+
+	n := len(pts)
+	switch n {
+	case 0:
+		return func(x float64) float64 { return x }
+	case 1:
+		return func(float64) float64 { return pts[0][1] }
+	}
+
+	// secants
+	d := make([]float64, n-1)
+	for i := range d {
+		if dx := pts[i+1][0] - pts[i][0]; dx > 0 {
+			d[i] = (pts[i+1][1] - pts[i][1]) / dx
+		}
+	}
+
+	// tangents
+	m := make([]float64, n)
+	m[0] = d[0]
+	m[n-1] = d[n-2]
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] > 0 {
+			m[i] = (d[i-1] + d[i]) / 2
+		}
+	}
+	for i, s := range d {
+		if s == 0 {
+			m[i] = 0
+			m[i+1] = 0
+			continue
+		}
+		a := m[i] / s
+		b := m[i+1] / s
+		if h := math.Hypot(a, b); h > 3 {
+			m[i] = 3 * a / h * s
+			m[i+1] = 3 * b / h * s
+		}
+	}
+
+	return func(x float64) float64 {
+		if x <= pts[0][0] {
+			return pts[0][1]
+		}
+		if x >= pts[n-1][0] {
+			return pts[n-1][1]
+		}
+
+		i := sort.Search(n, func(i int) bool { return pts[i][0] > x }) - 1
+		h := pts[i+1][0] - pts[i][0]
+		t := (x - pts[i][0]) / h
+		t2 := t * t
+		t3 := t2 * t
+		return (2*t3-3*t2+1)*pts[i][1] + (t3-2*t2+t)*h*m[i] +
+			(-2*t3+3*t2)*pts[i+1][1] + (t3-t2)*h*m[i+1]
+	}
+}
+
+func curveLUT(curve func(float64) float64) *[256]uint8 {
+	var lut [256]uint8
+	for i := range lut {
+		if curve == nil {
+			lut[i] = uint8(i)
+			continue
+		}
+		v := curve(float64(i) / 255)
+		lut[i] = uint8(math.Floor(255*math.Max(0, math.Min(v, 1)) + 0.5))
+	}
+	return &lut
+}
+
+// applyLUT maps the color channels of an image through lookup tables, in place.
+func applyLUT(dst *image.NRGBA, r, g, b *[256]uint8) {
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+3 : i+3]
+			p[0] = r[p[0]]
+			p[1] = g[p[1]]
+			p[2] = b[p[2]]
+			i += 4
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func Test_Curves(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	fillRandom(img.Pix, testSeed(t))
+
+	identity := func(x float64) float64 { return x }
+	for _, dst := range []*image.NRGBA{
+		Curves(img, nil, nil, nil),
+		Curves(img, identity, identity, identity),
+		Curves(img, SplineCurve(nil), SplineCurve([][2]float64{{0, 0}, {1, 1}}), SplineCurve([][2]float64{{1, 1}, {0.5, 0.5}, {0, 0}})),
+	} {
+		if string(dst.Pix) != string(img.Pix) {
+			t.Error("identity curves are not a no-op")
+		}
+	}
+
+	invert := func(x float64) float64 { return 1 - x }
+	dst := Curves(img, invert, nil, func(float64) float64 { return 2 })
+	for i := 0; i < len(img.Pix); i += 4 {
+		if dst.Pix[i+0] != 255-img.Pix[i+0] || dst.Pix[i+1] != img.Pix[i+1] || dst.Pix[i+2] != 255 || dst.Pix[i+3] != img.Pix[i+3] {
+			t.Fatalf("unexpected color at %d", i/4)
+		}
+	}
+}
+
+func Test_SplineCurve(t *testing.T) {
+	points := [][2]float64{{0, 0}, {0.25, 0.15}, {0.5, 0.5}, {0.75, 0.85}, {1, 1}}
+	curve := SplineCurve(points)
+
+	for _, p := range points {
+		if res := curve(p[0]); math.Abs(res-p[1]) > 1e-12 {
+			t.Errorf("at %v, expected: %v, got: %v", p[0], p[1], res)
+		}
+	}
+
+	prv := curve(0)
+	for i := 1; i <= 1000; i++ {
+		res := curve(float64(i) / 1000)
+		if res < prv || res < 0 || res > 1 {
+			t.Fatalf("at %v, not monotone or overshoots: %v", float64(i)/1000, res)
+		}
+		prv = res
+	}
+
+	if res := curve(-1); res != 0 {
+		t.Errorf("below range, got: %v", res)
+	}
+	if res := curve(2); res != 1 {
+		t.Errorf("above range, got: %v", res)
+	}
+}
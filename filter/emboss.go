@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Emboss renders an image as a relief, lit from a direction.
+//
+// The angle is the direction of the light, in radians, counterclockwise from the positive x axis.
+// Each color channel becomes its gradient along that direction, times depth, added to mid-gray:
+// flat regions become mid-gray, and edges become light or dark lines,
+// as they rise towards, or fall away from, the light.
+// With a depth of 1, a step from black to white is a white line next to a black line.
+//
+// As a stylization, it works on sRGB values, rather than linear light.
+// Alpha is preserved. The result is anchored at the origin.
+func Emboss(img image.Image, angle, depth float64) *image.NRGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+
+	pix := make([]float32, len(dst.Pix))
+	for i, v := range dst.Pix {
+		pix[i] = float32(v) / 255
+	}
+	pix = convolve(pix, w, h, embossKernel(angle), Clamp)
+
+	for i, v := range pix {
+		if i%4 != 3 {
+			dst.Pix[i] = embossValue(v, depth)
+		}
+	}
+	return dst
+}
+
+// EmbossGray renders the grayscale version of an image as a relief, lit from a direction,
+// like Emboss.
+func EmbossGray(img image.Image, angle, depth float64) *image.Gray {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+
+	pix := make([]float32, 4*len(dst.Pix))
+	for i, v := range dst.Pix {
+		pix[4*i] = float32(v) / 255
+	}
+	pix = convolve(pix, w, h, embossKernel(angle), Clamp)
+
+	for i := range dst.Pix {
+		dst.Pix[i] = embossValue(pix[4*i], depth)
+	}
+	return dst
+}
+
+// embossKernel computes a 3×3 kernel for the directional derivative along angle.
+// The kernel is normalized so that a ramp along angle measures its slope.
+func embossKernel(angle float64) [][]float64 {
+	// image y points down
+	dx, dy := math.Cos(angle), -math.Sin(angle)
+	kernel := make([][]float64, 3)
+	for ky := range kernel {
+		kernel[ky] = make([]float64, 3)
+		for kx := range kernel[ky] {
+			kernel[ky][kx] = (float64(kx-1)*dx + float64(ky-1)*dy) / 6
+		}
+	}
+	return kernel
+}
+
+func embossValue(g float32, depth float64) uint8 {
+	v := 0.5 + depth*float64(g)
+	return uint8(math.Floor(255*math.Max(0, math.Min(v, 1)) + 0.5))
+}
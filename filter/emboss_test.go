@@ -0,0 +1,63 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_Emboss(t *testing.T) {
+	// dark on the left, bright on the right
+	img := image.NewNRGBA(image.Rect(4, 4, 24, 14))
+	for y := 4; y < 14; y++ {
+		for x := 4; x < 24; x++ {
+			c := color.NRGBA{40, 40, 40, 200}
+			if x >= 14 {
+				c = color.NRGBA{211, 211, 211, 200}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	// lit from the right, the step rises towards the light
+	dst := Emboss(img, 0, 1)
+	if dst.Rect != image.Rect(0, 0, 20, 10) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			c := dst.NRGBAAt(x, y)
+			if c.A != 200 {
+				t.Fatalf("at %dx%d: alpha changed: %v", x, y, c)
+			}
+			switch x {
+			case 9, 10: // the step: half of 211-40 above mid-gray, 127.5
+				if c.R != 213 {
+					t.Fatalf("at %dx%d: expected a light line, got %v", x, y, c)
+				}
+			default:
+				if c.R != 128 {
+					t.Fatalf("at %dx%d: expected mid-gray, got %v", x, y, c)
+				}
+			}
+		}
+	}
+
+	// lit from the left, it's a dark line
+	if c := Emboss(img, math.Pi, 1).NRGBAAt(9, 5); c.R != 42 {
+		t.Errorf("expected a dark line, got %v", c)
+	}
+	// lit from above, there's no relief
+	if c := Emboss(img, math.Pi/2, 1).NRGBAAt(9, 5); c.R != 128 {
+		t.Errorf("expected mid-gray, got %v", c)
+	}
+
+	gray := EmbossGray(img, 0, 2)
+	if v := gray.GrayAt(10, 5).Y; v != 255 {
+		t.Errorf("expected white, got %d", v)
+	}
+	if v := gray.GrayAt(3, 5).Y; v != 128 {
+		t.Errorf("expected mid-gray, got %d", v)
+	}
+}
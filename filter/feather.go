@@ -0,0 +1,105 @@
+package filter
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/analysis"
+)
+
+// Feather softens the hard alpha edges of an image,
+// ramping alpha over radius pixels, based on the distance to the edge.
+//
+// A positive radius feathers inward: the shape keeps its extent,
+// and alpha fades in over radius pixels inside the edge.
+// A negative radius feathers outward: the shape grows by -radius pixels,
+// extending the colors of the edge, with alpha fading out.
+//
+// Pixels are considered inside the shape when their alpha is at least 50%.
+func Feather(img image.Image, radius int) *image.NRGBA {
+	dst := toNRGBA(img)
+	if radius == 0 {
+		return dst
+	}
+
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+	inside := image.NewGray(dst.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if dst.Pix[y*dst.Stride+4*x+3] >= 128 {
+				inside.Pix[y*inside.Stride+x] = 255
+			}
+		}
+	}
+
+	if radius > 0 {
+		dist := analysis.DistanceTransform(inside)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				d := dist.Gray16At(x, y).Y
+				if d == 0 || int(d) > radius {
+					continue
+				}
+				i := y*dst.Stride + 4*x + 3
+				f := float64(d) / float64(radius+1)
+				dst.Pix[i] = uint8(math.Floor(float64(dst.Pix[i])*f + 0.5))
+			}
+		}
+		return dst
+	}
+
+	radius = -radius
+	outside := image.NewGray(dst.Rect)
+	for i, p := range inside.Pix {
+		outside.Pix[i] = ^p
+	}
+	dist := analysis.DistanceTransform(outside)
+
+	// grow the colors of the shape into the feathered ring
+	colored := inside
+	for n := 0; n < radius; n++ {
+		next := image.NewGray(colored.Rect)
+		copy(next.Pix, colored.Pix)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if colored.Pix[y*w+x] != 0 || int(dist.Gray16At(x, y).Y) > radius {
+					continue
+				}
+				var r, g, b, c int
+				for v := y - 1; v <= y+1; v++ {
+					for u := x - 1; u <= x+1; u++ {
+						if u < 0 || v < 0 || u >= w || v >= h || colored.Pix[v*w+u] == 0 {
+							continue
+						}
+						p := dst.Pix[v*dst.Stride+4*u:]
+						r += int(p[0])
+						g += int(p[1])
+						b += int(p[2])
+						c++
+					}
+				}
+				if c > 0 {
+					p := dst.Pix[y*dst.Stride+4*x:]
+					p[0] = uint8((r + c/2) / c)
+					p[1] = uint8((g + c/2) / c)
+					p[2] = uint8((b + c/2) / c)
+					next.Pix[y*w+x] = 255
+				}
+			}
+		}
+		colored = next
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := dist.Gray16At(x, y).Y
+			if d == 0 || int(d) > radius {
+				continue
+			}
+			i := y*dst.Stride + 4*x + 3
+			f := 1 - float64(d)/float64(radius+1)
+			dst.Pix[i] = uint8(math.Floor(255*f + 0.5))
+		}
+	}
+	return dst
+}
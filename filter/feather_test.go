@@ -0,0 +1,63 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_Feather(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 41, 41))
+	for y := 0; y < 41; y++ {
+		for x := 0; x < 41; x++ {
+			if math.Hypot(float64(x-20), float64(y-20)) <= 10 {
+				img.SetNRGBA(x, y, color.NRGBA{200, 100, 50, 255})
+			}
+		}
+	}
+
+	inward := Feather(img, 3)
+	outward := Feather(img, -3)
+	for y := 0; y < 41; y++ {
+		for x := 0; x < 41; x++ {
+			d := math.Hypot(float64(x-20), float64(y-20))
+			ai := inward.NRGBAAt(x, y).A
+			ao := outward.NRGBAAt(x, y).A
+
+			switch {
+			case d <= 6:
+				if ai != 255 || ao != 255 {
+					t.Errorf("at %dx%d, not opaque: %d, %d", x, y, ai, ao)
+				}
+			case d <= 9:
+				if ai == 0 || ao != 255 {
+					t.Errorf("at %dx%d, unexpected: %d, %d", x, y, ai, ao)
+				}
+			case d <= 10:
+				if ai == 0 || ai == 255 || ao != 255 {
+					t.Errorf("at %dx%d, not in the inward ring: %d, %d", x, y, ai, ao)
+				}
+			case d <= 11:
+				if ai != 0 || ao == 0 || ao == 255 {
+					t.Errorf("at %dx%d, not in the outward ring: %d, %d", x, y, ai, ao)
+				}
+			case d <= 13:
+				if ai != 0 || ao == 255 {
+					t.Errorf("at %dx%d, unexpected: %d, %d", x, y, ai, ao)
+				}
+			case d >= 14:
+				if ai != 0 || ao != 0 {
+					t.Errorf("at %dx%d, not transparent: %d, %d", x, y, ai, ao)
+				}
+			}
+
+			if ao != 0 {
+				if c := outward.NRGBAAt(x, y); c.R != 200 || c.G != 100 || c.B != 50 {
+					t.Errorf("at %dx%d, unexpected color: %v", x, y, c)
+				}
+			}
+		}
+	}
+
+}
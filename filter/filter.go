@@ -0,0 +1,21 @@
+// Package filter applies effects and adjustments to images.
+//
+// The package works with the Image interface described in the image package.
+//
+// Filters return a new image, anchored at the origin, and never modify their input.
+// Where it matters, pixels are processed in linear light.
+package filter
+
+import (
+	"image"
+	"image/draw"
+)
+
+// toNRGBA converts an image to an NRGBA image anchored at the origin.
+// The returned image is always a copy.
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+	return dst
+}
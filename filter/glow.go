@@ -0,0 +1,42 @@
+package filter
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Glow adds a bloom to the bright regions of an image.
+//
+// Pixels brighter than threshold (by luma, in sRGB) are extracted,
+// blurred by a Gaussian of standard deviation blur (in pixels),
+// scaled by intensity, and screened over the image, in linear light.
+// Screening, rather than adding, keeps bright regions from blowing out.
+func Glow(img image.Image, threshold uint8, blur float64, intensity float64) *image.NRGBA {
+	src := toNRGBA(img)
+	lin := imageutil.ToLinearFloat(src)
+	w, h := lin.Rect.Dx(), lin.Rect.Dy()
+
+	bright := make([]float32, len(lin.Pix))
+	for y := 0; y < h; y++ {
+		s := src.Pix[y*src.Stride:]
+		for x := 0; x < w; x++ {
+			// same weights as color.GrayModel
+			luma := (19595*uint32(s[4*x]) + 38470*uint32(s[4*x+1]) + 7471*uint32(s[4*x+2]) + 1<<15) >> 16
+			if luma > uint32(threshold) {
+				i := y*lin.Stride + 4*x
+				copy(bright[i:i+4], lin.Pix[i:i+4])
+			}
+		}
+	}
+
+	glow := gaussianBlur(bright, w, h, 4, math.Max(0, blur))
+	k := float32(math.Max(0, intensity))
+	for i, g := range glow {
+		g = min(1, k*g)
+		c := lin.Pix[i]
+		lin.Pix[i] = c + g - c*g
+	}
+	return imageutil.FromLinearFloat(lin)
+}
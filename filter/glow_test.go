@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_Glow(t *testing.T) {
+	// a bright spot on black
+	img := image.NewNRGBA(image.Rect(0, 0, 31, 31))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i+3] = 255
+	}
+	for y := 14; y <= 16; y++ {
+		for x := 14; x <= 16; x++ {
+			copy(img.Pix[img.PixOffset(x, y):], []uint8{255, 255, 255, 255})
+		}
+	}
+
+	dst := Glow(img, 200, 3, 1)
+
+	if c := dst.NRGBAAt(15, 15); c.R != 255 || c.A != 255 {
+		t.Errorf("unexpected center: %v", c)
+	}
+
+	// the halo fades with distance
+	prev := uint8(255)
+	for x := 17; x < 31; x++ {
+		c := dst.NRGBAAt(x, 15)
+		if c.R != c.G || c.G != c.B || c.A != 255 {
+			t.Errorf("at %d, unexpected halo: %v", x, c)
+		}
+		if c.R > prev {
+			t.Errorf("at %d, halo doesn't fade", x)
+		}
+		prev = c.R
+	}
+	if c := dst.NRGBAAt(18, 15); c.R < 64 {
+		t.Errorf("halo is too weak: %v", c)
+	}
+	if c := dst.NRGBAAt(30, 15); c.R != 0 {
+		t.Errorf("halo is too wide: %v", c)
+	}
+
+	// nothing glows below the threshold
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{128, 128, 128, 255})
+	}
+	dst = Glow(img, 200, 3, 1)
+	if string(dst.Pix) != string(img.Pix) {
+		t.Error("dim image changed")
+	}
+}
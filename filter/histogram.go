@@ -0,0 +1,155 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// MatchHistogram remaps the colors of src so that the histogram of each channel matches that of reference,
+// e.g. to make a set of photos look consistent.
+//
+// Each channel is mapped through its cumulative distribution to the reference's, independently.
+// This can introduce color casts; see MatchHistogramLuminance.
+// Fully transparent pixels are ignored.
+func MatchHistogram(src, reference image.Image) *image.NRGBA {
+	dst := toNRGBA(src)
+	ref := toNRGBA(reference)
+
+	var sh, rh [3][256]int
+	channelHistograms(dst, &sh)
+	channelHistograms(ref, &rh)
+
+	r := matchCDF(&sh[0], &rh[0])
+	g := matchCDF(&sh[1], &rh[1])
+	b := matchCDF(&sh[2], &rh[2])
+	applyLUT(dst, r, g, b)
+	return dst
+}
+
+// MatchHistogramLuminance remaps the luma of src so that its histogram matches that of reference,
+// leaving chroma (YCbCr's Cb and Cr) unchanged, which avoids color casts.
+// Fully transparent pixels are ignored.
+// See MatchHistogram.
+func MatchHistogramLuminance(src, reference image.Image) *image.NRGBA {
+	dst := toNRGBA(src)
+	ref := toNRGBA(reference)
+
+	var sh, rh [256]int
+	lumaHistogram(dst, &sh)
+	lumaHistogram(ref, &rh)
+
+	lut := matchCDF(&sh, &rh)
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+3 : i+3]
+			l, cb, cr := color.RGBToYCbCr(p[0], p[1], p[2])
+			if m := lut[l]; m != l {
+				p[0], p[1], p[2] = color.YCbCrToRGB(m, cb, cr)
+			}
+			i += 4
+		}
+	}
+	return dst
+}
+
+// ChromaticityScatter bins the chromaticities of the pixels of an image into a size×size density image,
+// e.g. to visualize its color distribution, or a color cast.
+//
+// Chromaticity is measured in the a*b* plane of CIE L*a*b*, over [-128, 128[ on both axes:
+// a* increases to the right (green to red), and b* upwards (blue to yellow),
+// so neutral colors fall at the center.
+// Densities are scaled so the densest bin is 255. Fully transparent pixels are ignored.
+func ChromaticityScatter(img image.Image, size int) *image.Gray {
+	if size <= 0 {
+		panic("Invalid size")
+	}
+
+	src := toNRGBA(img)
+	bin := func(v float64) int {
+		return max(0, min(int(math.Floor((v+128)*float64(size)/256)), size-1))
+	}
+
+	counts := make([]int, size*size)
+	for y := 0; y < src.Rect.Dy(); y++ {
+		i := y * src.Stride
+		for x := 0; x < src.Rect.Dx(); x++ {
+			p := src.Pix[i : i+4 : i+4]
+			if p[3] != 0 {
+				_, a, b := rgbToLab(float64(p[0])/255, float64(p[1])/255, float64(p[2])/255)
+				counts[(size-1-bin(b))*size+bin(a)]++
+			}
+			i += 4
+		}
+	}
+
+	dst := image.NewGray(image.Rect(0, 0, size, size))
+	if peak := slices.Max(counts); peak > 0 {
+		for i, n := range counts {
+			dst.Pix[i] = uint8((255*n + peak/2) / peak)
+		}
+	}
+	return dst
+}
+
+func channelHistograms(img *image.NRGBA, hist *[3][256]int) {
+	for y := 0; y < img.Rect.Dy(); y++ {
+		i := y * img.Stride
+		for x := 0; x < img.Rect.Dx(); x++ {
+			p := img.Pix[i : i+4 : i+4]
+			if p[3] != 0 {
+				hist[0][p[0]]++
+				hist[1][p[1]]++
+				hist[2][p[2]]++
+			}
+			i += 4
+		}
+	}
+}
+
+func lumaHistogram(img *image.NRGBA, hist *[256]int) {
+	for y := 0; y < img.Rect.Dy(); y++ {
+		i := y * img.Stride
+		for x := 0; x < img.Rect.Dx(); x++ {
+			p := img.Pix[i : i+4 : i+4]
+			if p[3] != 0 {
+				l, _, _ := color.RGBToYCbCr(p[0], p[1], p[2])
+				hist[l]++
+			}
+			i += 4
+		}
+	}
+}
+
+// matchCDF maps each value to the smallest reference value with an equal or greater cumulative frequency.
+// Matching a histogram to itself is the identity, for the values it contains.
+func matchCDF(src, ref *[256]int) *[256]uint8 {
+	var lut [256]uint8
+
+	var sn, rn int
+	for i := range src {
+		sn += src[i]
+		rn += ref[i]
+	}
+	if sn == 0 || rn == 0 {
+		for i := range lut {
+			lut[i] = uint8(i)
+		}
+		return &lut
+	}
+
+	var scum, rcum, j int
+	rcum = ref[0]
+	for i := range src {
+		scum += src[i]
+		// scum/sn <= rcum/rn, without division
+		for j < 255 && rcum*sn < scum*rn {
+			j++
+			rcum += ref[j]
+		}
+		lut[i] = uint8(j)
+	}
+	return &lut
+}
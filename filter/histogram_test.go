@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_MatchHistogram(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	fillRandom(img.Pix, testSeed(t))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	// matching an image to itself is the identity
+	if dst := MatchHistogram(img, img); string(dst.Pix) != string(img.Pix) {
+		t.Error("per channel self match is not the identity")
+	}
+	dst := MatchHistogramLuminance(img, img)
+	for i, p := range dst.Pix {
+		if abs(int(p)-int(img.Pix[i])) > 2 {
+			t.Fatalf("luminance self match is not near the identity: %d, %d", p, img.Pix[i])
+		}
+	}
+
+	// matching a dark image to a bright reference brightens it
+	dark := image.NewNRGBA(img.Rect)
+	bright := image.NewNRGBA(img.Rect)
+	for i := range img.Pix {
+		if i%4 == 3 {
+			dark.Pix[i], bright.Pix[i] = 255, 255
+		} else {
+			dark.Pix[i] = img.Pix[i] / 4
+			bright.Pix[i] = 128 + img.Pix[i]/2
+		}
+	}
+
+	mean := func(img *image.NRGBA) (sum int) {
+		for i, p := range img.Pix {
+			if i%4 != 3 {
+				sum += int(p)
+			}
+		}
+		return sum / (3 * 32 * 32)
+	}
+
+	for _, dst := range []*image.NRGBA{MatchHistogram(dark, bright), MatchHistogramLuminance(dark, bright)} {
+		if m0, m1, m2 := mean(dark), mean(dst), mean(bright); m1 < m2-16 || m1 <= m0 {
+			t.Errorf("not brightened: %d, %d, %d", m0, m1, m2)
+		}
+	}
+}
+
+func Test_ChromaticityScatter(t *testing.T) {
+	// a reddish and a bluish color, 84 to 32 pixels, and transparent pixels that are ignored
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			c := color.NRGBA{200, 40, 40, 255}
+			switch {
+			case x >= 12:
+				c = color.NRGBA{40, 60, 200, 255}
+			case y == 0:
+				c = color.NRGBA{0, 255, 0, 0}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	const size = 32
+	res := ChromaticityScatter(img, size)
+	if res.Rect != image.Rect(0, 0, size, size) {
+		t.Fatalf("unexpected bounds: %v", res.Rect)
+	}
+
+	_, ra, rb := rgbToLab(200.0/255, 40.0/255, 40.0/255)
+	_, ba, bb := rgbToLab(40.0/255, 60.0/255, 200.0/255)
+	red := image.Pt(int((ra+128)*size/256), size-1-int((rb+128)*size/256))
+	blue := image.Pt(int((ba+128)*size/256), size-1-int((bb+128)*size/256))
+	if red.X <= size/2 || red.Y >= size/2 || blue.Y <= size/2 {
+		t.Fatalf("unexpected positions: %v, %v", red, blue)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var want uint8
+			switch image.Pt(x, y) {
+			case red:
+				want = 255
+			case blue:
+				want = 97 // 255 * 32/84
+			}
+			if got := res.GrayAt(x, y).Y; got != want {
+				t.Errorf("(%d, %d): expected %d, got %d", x, y, want, got)
+			}
+		}
+	}
+}
+
+func Test_matchCDF(t *testing.T) {
+	var src, ref [256]int
+	src[10], src[20] = 5, 5 // two equal peaks
+	ref[100], ref[200] = 5, 5
+
+	lut := matchCDF(&src, &ref)
+	if lut[10] != 100 || lut[20] != 200 {
+		t.Errorf("unexpected mapping: %d, %d", lut[10], lut[20])
+	}
+}
@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"math"
+)
+
+// rgbToHSL converts sRGB encoded values in [0, 1] to hue (in degrees, [0, 360[),
+// saturation and lightness (in [0, 1]).
+func rgbToHSL(r, g, b float64) (h, s, l float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+
+	c := max - min
+	if c == 0 {
+		return 0, 0, l
+	}
+	s = c / (1 - math.Abs(2*l-1))
+
+	switch max {
+	case r:
+		h = math.Mod((g-b)/c+6, 6)
+	case g:
+		h = (b-r)/c + 2
+	default:
+		h = (r-g)/c + 4
+	}
+	return 60 * h, s, l
+}
+
+// hslToRGB converts hue (in degrees), saturation and lightness (in [0, 1])
+// to sRGB encoded values in [0, 1].
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	switch int(h) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	m := l - c/2
+	return r + m, g + m, b + m
+}
@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"testing"
+)
+
+func Test_HSL(t *testing.T) {
+	for r := 0; r < 256; r += 5 {
+		for g := 0; g < 256; g += 3 {
+			for b := 0; b < 256; b += 7 {
+				h, s, l := rgbToHSL(float64(r)/255, float64(g)/255, float64(b)/255)
+				if h < 0 || h >= 360 || s < 0 || s > 1+1e-9 || l < 0 || l > 1 {
+					t.Fatalf("%d,%d,%d: out of range: %v,%v,%v", r, g, b, h, s, l)
+				}
+				rr, gg, bb := hslToRGB(h, s, l)
+				if unit8(rr) != uint8(r) || unit8(gg) != uint8(g) || unit8(bb) != uint8(b) {
+					t.Fatalf("%d,%d,%d: round trip failed: %v,%v,%v", r, g, b, rr, gg, bb)
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		r, g, b float64
+		h, s, l float64
+	}{
+		{1, 0, 0, 0, 1, 0.5},
+		{0, 1, 0, 120, 1, 0.5},
+		{0, 0, 1, 240, 1, 0.5},
+		{1, 1, 0, 60, 1, 0.5},
+		{1, 0, 1, 300, 1, 0.5},
+		{0.5, 0.5, 0.5, 0, 0, 0.5},
+	}
+	for _, tt := range tests {
+		h, s, l := rgbToHSL(tt.r, tt.g, tt.b)
+		if h != tt.h || s != tt.s || l != tt.l {
+			t.Errorf("%v,%v,%v: expected: %v,%v,%v, got: %v,%v,%v", tt.r, tt.g, tt.b, tt.h, tt.s, tt.l, h, s, l)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_Kuwahara(t *testing.T) {
+	// a noisy dark left half, and a noisy light right half
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	noise := make([]uint8, len(img.Pix))
+	fillRandom(noise, testSeed(t))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			v := 40 + noise[img.PixOffset(x, y)]%16
+			if x >= 20 {
+				v += 160
+			}
+			copy(img.Pix[img.PixOffset(x, y):], []uint8{v, v, v, 255})
+		}
+	}
+
+	variance := func(img *image.NRGBA, x0, x1 int) float64 {
+		var sum, sqr, n float64
+		for y := 0; y < 20; y++ {
+			for x := x0; x < x1; x++ {
+				v := float64(img.Pix[img.PixOffset(x, y)])
+				sum += v
+				sqr += v * v
+				n++
+			}
+		}
+		return sqr/n - (sum/n)*(sum/n)
+	}
+
+	dst := Kuwahara(img, 3)
+
+	// flat regions are smoothed
+	if v0, v1 := variance(img, 0, 20), variance(dst, 0, 20); v1 > v0/4 {
+		t.Errorf("left half not smoothed: %v, %v", v0, v1)
+	}
+	if v0, v1 := variance(img, 20, 40), variance(dst, 20, 40); v1 > v0/4 {
+		t.Errorf("right half not smoothed: %v, %v", v0, v1)
+	}
+
+	// the edge is preserved
+	for y := 0; y < 20; y++ {
+		l, r := dst.NRGBAAt(19, y), dst.NRGBAAt(20, y)
+		if l.R > 60 || r.R < 195 || l.A != 255 || r.A != 255 {
+			t.Errorf("at %d, edge not preserved: %v, %v", y, l, r)
+		}
+	}
+}
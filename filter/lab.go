@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"math"
+)
+
+// D65 reference white
+const whiteX, whiteY, whiteZ = 0.95047, 1.0, 1.08883
+
+// srgbToLinear converts an sRGB encoded value in [0, 1] to linear light.
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts a linear light value in [0, 1] to sRGB encoding.
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// rgbToLab converts sRGB encoded values in [0, 1] to CIE L*a*b* (D65).
+func rgbToLab(r, g, b float64) (l, a, bb float64) {
+	r, g, b = srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / whiteX
+	y := (0.2126729*r + 0.7151522*g + 0.0721750*b) / whiteY
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / whiteZ
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// labToRGB converts CIE L*a*b* (D65) to sRGB encoded values, unclamped.
+func labToRGB(l, a, bb float64) (r, g, b float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - bb/200
+
+	x := labFInv(fx) * whiteX
+	y := labFInv(fy) * whiteY
+	z := labFInv(fz) * whiteZ
+
+	r = +3.2404542*x - 1.5371385*y - 0.4985314*z
+	g = -0.9692660*x + 1.8760108*y + 0.0415560*z
+	b = +0.0556434*x - 0.2040259*y + 1.0572252*z
+	return linearToSRGB(math.Max(0, r)), linearToSRGB(math.Max(0, g)), linearToSRGB(math.Max(0, b))
+}
+
+func labF(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d*d*d {
+		return math.Cbrt(t)
+	}
+	return t/(3*d*d) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	const d = 6.0 / 29
+	if t > d {
+		return t * t * t
+	}
+	return 3 * d * d * (t - 4.0/29)
+}
+
+// deltaE is the CIE76 color difference between two sRGB encoded colors in [0, 1].
+func deltaE(r0, g0, b0, r1, g1, b1 float64) float64 {
+	l0, a0, bb0 := rgbToLab(r0, g0, b0)
+	l1, a1, bb1 := rgbToLab(r1, g1, b1)
+	return math.Sqrt((l1-l0)*(l1-l0) + (a1-a0)*(a1-a0) + (bb1-bb0)*(bb1-bb0))
+}
@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_Lab(t *testing.T) {
+	tests := []struct {
+		r, g, b  float64
+		l, a, bb float64
+	}{
+		{1, 1, 1, 100, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+		{1, 0, 0, 53.24, 80.09, 67.20},
+		{0, 1, 0, 87.73, -86.18, 83.18},
+		{0, 0, 1, 32.30, 79.19, -107.86},
+	}
+	for _, tt := range tests {
+		l, a, bb := rgbToLab(tt.r, tt.g, tt.b)
+		if math.Abs(l-tt.l) > 0.01 || math.Abs(a-tt.a) > 0.01 || math.Abs(bb-tt.bb) > 0.01 {
+			t.Errorf("%v,%v,%v: expected: %v,%v,%v, got: %v,%v,%v", tt.r, tt.g, tt.b, tt.l, tt.a, tt.bb, l, a, bb)
+		}
+	}
+
+	for r := 0; r < 256; r += 5 {
+		for g := 0; g < 256; g += 3 {
+			for b := 0; b < 256; b += 7 {
+				l, a, bb := rgbToLab(float64(r)/255, float64(g)/255, float64(b)/255)
+				rr, gg, bb2 := labToRGB(l, a, bb)
+				if unit8(rr) != uint8(r) || unit8(gg) != uint8(g) || unit8(bb2) != uint8(b) {
+					t.Fatalf("%d,%d,%d: round trip failed: %v,%v,%v", r, g, b, rr, gg, bb2)
+				}
+			}
+		}
+	}
+}
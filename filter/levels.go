@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"image"
+	"math"
+)
+
+// Levels remaps the tones of an image, with the semantics of Photoshop's Levels.
+//
+// The input range [inBlack, inWhite] is stretched to [0, 1] and clamped,
+// then gamma is applied (values above 1 brighten midtones), and the result
+// is mapped to the output range [outBlack, outWhite].
+// Black and white points are sRGB encoded 8-bit levels, in [0, 255].
+// The same adjustment is applied to every color channel.
+//
+// Inverted ranges (black above white) invert the image.
+// If inBlack equals inWhite, the input is thresholded at that level.
+// A gamma that is not positive is treated as 1.
+func Levels(img image.Image, inBlack, inWhite, gamma, outBlack, outWhite float64) *image.NRGBA {
+	if !(gamma > 0) {
+		gamma = 1
+	}
+
+	lut := curveLUT(func(x float64) float64 {
+		x *= 255
+
+		var v float64
+		if inWhite == inBlack {
+			if x >= inBlack {
+				v = 1
+			}
+		} else {
+			v = (x - inBlack) / (inWhite - inBlack)
+			v = math.Max(0, math.Min(v, 1))
+		}
+
+		v = math.Pow(v, 1/gamma)
+		return (outBlack + v*(outWhite-outBlack)) / 255
+	})
+
+	dst := toNRGBA(img)
+	applyLUT(dst, lut, lut, lut)
+	return dst
+}
@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_Levels(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 256, 1))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i)
+	}
+
+	tests := []struct {
+		inBlack, inWhite, gamma, outBlack, outWhite float64
+		exp                                         map[int]uint8
+	}{
+		{0, 255, 1, 0, 255, map[int]uint8{0: 0, 1: 1, 128: 128, 255: 255}},
+		{20, 200, 1, 0, 255, map[int]uint8{0: 0, 20: 0, 110: 128, 200: 255, 255: 255}},
+		{0, 255, 1, 50, 200, map[int]uint8{0: 50, 255: 200, 51: 80}},
+		{20, 200, 2, 10, 250, map[int]uint8{0: 10, 20: 10, 65: 130, 200: 250, 255: 250}},
+		{255, 0, 1, 0, 255, map[int]uint8{0: 255, 255: 0, 100: 155}},
+		{0, 255, 1, 255, 0, map[int]uint8{0: 255, 255: 0, 100: 155}},
+		{128, 128, 1, 0, 255, map[int]uint8{0: 0, 127: 0, 128: 255, 255: 255}},
+		{0, 255, 0, 0, 255, map[int]uint8{0: 0, 64: 64, 255: 255}},
+	}
+
+	for _, tt := range tests {
+		dst := Levels(img, tt.inBlack, tt.inWhite, tt.gamma, tt.outBlack, tt.outWhite)
+		for in, exp := range tt.exp {
+			c := dst.NRGBAAt(in, 0)
+			if c.R != exp || c.G != exp || c.B != exp || c.A != 255 {
+				t.Errorf("%v: at %d, expected: %d, got: %v", tt, in, exp, c)
+			}
+		}
+	}
+}
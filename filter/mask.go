@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"image"
+	"math"
+)
+
+// RoundedCorners masks the corners of an image with an antialiased rounded rectangle.
+// Radius is clamped to half the smallest dimension of the image.
+func RoundedCorners(img image.Image, radius int) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	w := float64(dst.Rect.Dx())
+	h := float64(dst.Rect.Dy())
+	r := math.Max(0, math.Min(float64(radius), math.Min(w, h)/2))
+
+	maskShape(dst, func(px, py float64) float64 {
+		qx := math.Max(0, math.Max(r-px, px-(w-r)))
+		qy := math.Max(0, math.Max(r-py, py-(h-r)))
+		if qx == 0 || qy == 0 {
+			return math.Inf(-1)
+		}
+		return math.Hypot(qx, qy) - r
+	})
+	return dst
+}
+
+// CircleCrop crops an image to its centered square, then masks it with an antialiased circle.
+func CircleCrop(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	if size > bounds.Dy() {
+		size = bounds.Dy()
+	}
+
+	min := bounds.Min.Add(image.Pt((bounds.Dx()-size)/2, (bounds.Dy()-size)/2))
+	dst := toNRGBA(crop{img, image.Rectangle{min, min.Add(image.Pt(size, size))}})
+
+	r := float64(size) / 2
+	maskShape(dst, func(px, py float64) float64 {
+		return math.Hypot(px-r, py-r) - r
+	})
+	return dst
+}
+
+// maskShape multiplies the alpha of each pixel by its coverage of a shape.
+// The shape is given by the signed distance (negative inside) from the pixel center to its edge.
+func maskShape(dst *image.NRGBA, dist func(px, py float64) float64) {
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y*dst.Stride + 3
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			cover := 0.5 - dist(float64(x)+0.5, float64(y)+0.5)
+			switch {
+			case cover <= 0:
+				dst.Pix[i] = 0
+			case cover < 1:
+				dst.Pix[i] = uint8(math.Floor(float64(dst.Pix[i])*cover + 0.5))
+			}
+			i += 4
+		}
+	}
+}
+
+// crop restricts the bounds of an image, without copying.
+type crop struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (c crop) Bounds() image.Rectangle {
+	return c.rect.Intersect(c.Image.Bounds())
+}
@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_RoundedCorners(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	dst := RoundedCorners(img, 8)
+	if dst.Rect != img.Rect {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for _, pt := range []image.Point{{0, 0}, {39, 0}, {0, 29}, {39, 29}, {1, 1}, {38, 28}} {
+		if a := dst.NRGBAAt(pt.X, pt.Y).A; a != 0 {
+			t.Errorf("at %v, not transparent: %d", pt, a)
+		}
+	}
+	for _, pt := range []image.Point{{20, 15}, {20, 0}, {0, 15}, {8, 8}, {31, 21}} {
+		if a := dst.NRGBAAt(pt.X, pt.Y).A; a != 255 {
+			t.Errorf("at %v, not opaque: %d", pt, a)
+		}
+	}
+
+	var partial bool
+	for x := 0; x < 8; x++ {
+		if a := dst.NRGBAAt(x, 2).A; a != 0 && a != 255 {
+			partial = true
+		}
+	}
+	if !partial {
+		t.Error("edge not antialiased")
+	}
+}
+
+func Test_CircleCrop(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 45, 35))
+	for y := 5; y < 35; y++ {
+		for x := 5; x < 45; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	dst := CircleCrop(img)
+	if dst.Rect != image.Rect(0, 0, 30, 30) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for _, pt := range []image.Point{{0, 0}, {29, 0}, {0, 29}, {29, 29}, {3, 3}} {
+		if a := dst.NRGBAAt(pt.X, pt.Y).A; a != 0 {
+			t.Errorf("at %v, not transparent: %d", pt, a)
+		}
+	}
+	if res := dst.NRGBAAt(15, 15); res != (color.NRGBA{25, 20, 0, 255}) {
+		t.Errorf("center, unexpected: %v", res)
+	}
+	if a := dst.NRGBAAt(15, 0).A; a == 0 {
+		t.Error("top edge fully transparent")
+	}
+}
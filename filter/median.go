@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"image"
+	"sort"
+)
+
+// Median replaces each channel of each pixel of an image with the median
+// of its (2*radius+1)×(2*radius+1) square neighborhood, removing salt-and-pepper noise.
+// The neighborhood shrinks at the edges of the image;
+// for neighborhoods with an even number of pixels, the upper median is used.
+func Median(img image.Image, radius int) *image.NRGBA {
+	src := toNRGBA(img)
+	if radius <= 0 {
+		return src
+	}
+
+	dst := image.NewNRGBA(src.Rect)
+	if radius == 1 {
+		median3x3(dst, src)
+	} else {
+		medianHistogram(dst, src, radius)
+	}
+	return dst
+}
+
+// median3x3 uses a sorting network in the interior, and sorting at the edges.
+func median3x3(dst, src *image.NRGBA) {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	var buf [9]uint8
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*dst.Stride + 4*x
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				for c := 0; c < 4; c++ {
+					v := buf[:0]
+					for yy := max(y-1, 0); yy <= min(y+1, h-1); yy++ {
+						for xx := max(x-1, 0); xx <= min(x+1, w-1); xx++ {
+							v = append(v, src.Pix[yy*src.Stride+4*xx+c])
+						}
+					}
+					sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+					dst.Pix[i+c] = v[len(v)/2]
+				}
+				continue
+			}
+
+			for c := 0; c < 4; c++ {
+				j := i + c
+				s := src.Stride
+				buf = [9]uint8{
+					src.Pix[j-s-4], src.Pix[j-s], src.Pix[j-s+4],
+					src.Pix[j-4], src.Pix[j], src.Pix[j+4],
+					src.Pix[j+s-4], src.Pix[j+s], src.Pix[j+s+4],
+				}
+				dst.Pix[j] = median9(&buf)
+			}
+		}
+	}
+}
+
+// median9 finds the median of 9 values with a sorting network.
+func median9(p *[9]uint8) uint8 {
+	sort2 := func(a, b int) {
+		if p[a] > p[b] {
+			p[a], p[b] = p[b], p[a]
+		}
+	}
+	sort2(1, 2)
+	sort2(4, 5)
+	sort2(7, 8)
+	sort2(0, 1)
+	sort2(3, 4)
+	sort2(6, 7)
+	sort2(1, 2)
+	sort2(4, 5)
+	sort2(7, 8)
+	sort2(0, 3)
+	sort2(5, 8)
+	sort2(4, 7)
+	sort2(3, 6)
+	sort2(1, 4)
+	sort2(2, 5)
+	sort2(4, 7)
+	sort2(4, 2)
+	sort2(6, 4)
+	sort2(4, 2)
+	return p[4]
+}
+
+// medianHistogram uses a sliding window histogram (Huang's algorithm).
+func medianHistogram(dst, src *image.NRGBA, radius int) {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	var hist [4][256]int
+	for y := 0; y < h; y++ {
+		y0, y1 := max(y-radius, 0), min(y+radius, h-1)
+
+		hist = [4][256]int{}
+		count := 0
+		column := func(x, delta int) {
+			for yy := y0; yy <= y1; yy++ {
+				p := src.Pix[yy*src.Stride+4*x:]
+				hist[0][p[0]] += delta
+				hist[1][p[1]] += delta
+				hist[2][p[2]] += delta
+				hist[3][p[3]] += delta
+			}
+			count += delta * (y1 - y0 + 1)
+		}
+
+		for x := 0; x <= min(radius, w-1); x++ {
+			column(x, +1)
+		}
+
+		for x := 0; x < w; x++ {
+			if x > 0 {
+				if x+radius < w {
+					column(x+radius, +1)
+				}
+				if x-radius-1 >= 0 {
+					column(x-radius-1, -1)
+				}
+			}
+
+			i := y*dst.Stride + 4*x
+			for c := 0; c < 4; c++ {
+				var sum int
+				for v, n := range hist[c] {
+					sum += n
+					if sum > count/2 {
+						dst.Pix[i+c] = uint8(v)
+						break
+					}
+				}
+			}
+		}
+	}
+}
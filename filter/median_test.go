@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"testing"
+)
+
+func Test_Median(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			if x < 10 {
+				img.SetNRGBA(x, y, color.NRGBA{50, 60, 70, 255})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{200, 210, 220, 255})
+			}
+		}
+	}
+	clean := toNRGBA(img)
+	img.SetNRGBA(4, 5, color.NRGBA{255, 255, 255, 255})
+	img.SetNRGBA(15, 12, color.NRGBA{0, 0, 0, 0})
+	img.SetNRGBA(0, 0, color.NRGBA{255, 0, 255, 255})
+
+	for _, radius := range []int{1, 2, 3} {
+		dst := Median(img, radius)
+		if string(dst.Pix) != string(clean.Pix) {
+			t.Errorf("radius %d: outliers not removed, or edge not preserved", radius)
+		}
+	}
+
+	// a box blur smears the edge
+	var boxErr int
+	for y := 1; y < 19; y++ {
+		for x := 9; x <= 10; x++ {
+			var sum int
+			for v := y - 1; v <= y+1; v++ {
+				for u := x - 1; u <= x+1; u++ {
+					sum += int(clean.NRGBAAt(u, v).R)
+				}
+			}
+			boxErr += abs(sum/9 - int(clean.NRGBAAt(x, y).R))
+		}
+	}
+	if boxErr == 0 {
+		t.Error("box blur preserved the edge")
+	}
+}
+
+func Test_MedianBrute(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 13, 11))
+	fillRandom(img.Pix, testSeed(t))
+
+	for _, radius := range []int{1, 2, 4, 20} {
+		dst := Median(img, radius)
+		for y := 0; y < 11; y++ {
+			for x := 0; x < 13; x++ {
+				for c := 0; c < 4; c++ {
+					var v []int
+					for yy := y - radius; yy <= y+radius; yy++ {
+						for xx := x - radius; xx <= x+radius; xx++ {
+							if xx >= 0 && yy >= 0 && xx < 13 && yy < 11 {
+								v = append(v, int(img.Pix[img.PixOffset(xx, yy)+c]))
+							}
+						}
+					}
+					sort.Ints(v)
+					if exp, res := v[len(v)/2], int(dst.Pix[dst.PixOffset(x, y)+c]); exp != res {
+						t.Fatalf("radius %d, at %dx%d/%d, expected: %d, got: %d", radius, x, y, c, exp, res)
+					}
+				}
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
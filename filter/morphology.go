@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"image"
+)
+
+// Erode replaces each pixel of a grayscale image with the minimum
+// of its (2*radius+1)×(2*radius+1) square neighborhood.
+// The neighborhood shrinks at the edges of the image.
+// A negative radius causes a panic.
+func Erode(img *image.Gray, radius int) *image.Gray {
+	return morph(img, radius, false)
+}
+
+// Dilate replaces each pixel of a grayscale image with the maximum
+// of its (2*radius+1)×(2*radius+1) square neighborhood.
+// The neighborhood shrinks at the edges of the image.
+// A negative radius causes a panic.
+func Dilate(img *image.Gray, radius int) *image.Gray {
+	return morph(img, radius, true)
+}
+
+// Open erodes, then dilates, a grayscale image.
+// It removes bright features smaller than the neighborhood.
+func Open(img *image.Gray, radius int) *image.Gray {
+	return Dilate(Erode(img, radius), radius)
+}
+
+// Close dilates, then erodes, a grayscale image.
+// It fills dark holes smaller than the neighborhood.
+func Close(img *image.Gray, radius int) *image.Gray {
+	return Erode(Dilate(img, radius), radius)
+}
+
+func morph(img *image.Gray, radius int, max bool) *image.Gray {
+	if radius < 0 {
+		panic("Invalid radius")
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	tmp := image.NewGray(image.Rect(0, 0, w, h))
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+
+	// horizontal pass
+	src := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y):]
+	for y := 0; y < h; y++ {
+		minmax(tmp.Pix[y*tmp.Stride:], 1, src[y*img.Stride:], 1, w, radius, max)
+	}
+
+	// vertical pass
+	for x := 0; x < w; x++ {
+		minmax(dst.Pix[x:], dst.Stride, tmp.Pix[x:], tmp.Stride, h, radius, max)
+	}
+
+	return dst
+}
+
+func minmax(dst []uint8, dst_step int, src []uint8, src_step int, count, radius int, max bool) {
+	for i := 0; i < count; i++ {
+		lo := i - radius
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i + radius
+		if hi >= count {
+			hi = count - 1
+		}
+
+		v := src[lo*src_step]
+		for j := lo + 1; j <= hi; j++ {
+			s := src[j*src_step]
+			if max == (s > v) {
+				v = s
+			}
+		}
+		dst[i*dst_step] = v
+	}
+}
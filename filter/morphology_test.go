@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Morphology(t *testing.T) {
+	img := image.NewGray(image.Rect(3, 3, 23, 23))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.SetGray(10, 10, gray(0))  // hole
+	img.SetGray(15, 16, gray(10)) // hole
+
+	dst := Close(img, 1)
+	if dst.Rect != image.Rect(0, 0, 20, 20) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for i, p := range dst.Pix {
+		if p != 255 {
+			t.Fatalf("hole not filled at %d: %d", i, p)
+		}
+	}
+
+	dst = Erode(img, 1)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			exp := uint8(255)
+			if x >= 6 && x <= 8 && y >= 6 && y <= 8 {
+				exp = 0
+			}
+			if x >= 11 && x <= 13 && y >= 12 && y <= 14 {
+				exp = 10
+			}
+			if res := dst.GrayAt(x, y).Y; res != exp {
+				t.Fatalf("erode at %dx%d, expected: %d, got: %d", x, y, exp, res)
+			}
+		}
+	}
+
+	spot := image.NewGray(image.Rect(0, 0, 9, 9))
+	spot.SetGray(4, 4, gray(255))
+	spot.SetGray(0, 0, gray(255))
+
+	dst = Dilate(spot, 2)
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			exp := uint8(0)
+			if x >= 2 && x <= 6 && y >= 2 && y <= 6 || x <= 2 && y <= 2 {
+				exp = 255
+			}
+			if res := dst.GrayAt(x, y).Y; res != exp {
+				t.Fatalf("dilate at %dx%d, expected: %d, got: %d", x, y, exp, res)
+			}
+		}
+	}
+
+	dst = Open(spot, 1)
+	for i, p := range dst.Pix {
+		if p != 0 {
+			t.Fatalf("speck not removed at %d: %d", i, p)
+		}
+	}
+
+	// a zero radius copies, a negative radius panics
+	if dst := Erode(spot, 0); string(dst.Pix) != string(spot.Pix) {
+		t.Error("expected a copy")
+	}
+	for _, f := range []func(*image.Gray, int) *image.Gray{Erode, Dilate, Open, Close} {
+		func() {
+			defer func() {
+				if recover() != "Invalid radius" {
+					t.Error("expected a panic")
+				}
+			}()
+			f(spot, -1)
+		}()
+	}
+}
+
+func gray(y uint8) color.Gray {
+	return color.Gray{y}
+}
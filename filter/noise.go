@@ -0,0 +1,52 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"math/rand"
+)
+
+// AddNoise adds Gaussian noise to the color channels of an image, e.g. to emulate film grain,
+// or to mask banding.
+//
+// The amount is the standard deviation of the noise, on sRGB encoded values normalized to [0, 1].
+// Monochrome noise adds the same value to every channel of a pixel (luminance grain);
+// otherwise each channel gets its own noise.
+// Noise is generated from seed, so results are reproducible.
+// Results are clamped to [0, 1]. Alpha is preserved.
+func AddNoise(img image.Image, amount float64, monochrome bool, seed int64) *image.NRGBA {
+	return addNoise(img, amount, monochrome, seed, (*rand.Rand).NormFloat64)
+}
+
+// AddUniformNoise adds uniformly distributed noise, in [-amount, amount], to the color channels of an image,
+// like AddNoise.
+func AddUniformNoise(img image.Image, amount float64, monochrome bool, seed int64) *image.NRGBA {
+	return addNoise(img, amount, monochrome, seed, func(rnd *rand.Rand) float64 {
+		return 2*rnd.Float64() - 1
+	})
+}
+
+func addNoise(img image.Image, amount float64, monochrome bool, seed int64, sample func(*rand.Rand) float64) *image.NRGBA {
+	dst := toNRGBA(img)
+	if amount == 0 {
+		return dst
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+3 : i+3]
+			n := amount * sample(rnd)
+			for c := range p {
+				if c > 0 && !monochrome {
+					n = amount * sample(rnd)
+				}
+				v := float64(p[c])/255 + n
+				p[c] = uint8(math.Floor(255*math.Max(0, math.Min(v, 1)) + 0.5))
+			}
+			i += 4
+		}
+	}
+	return dst
+}
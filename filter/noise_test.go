@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func Test_AddNoise(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(2, 2, 66, 66))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+
+	for _, noise := range []func(image.Image, float64, bool, int64) *image.NRGBA{AddNoise, AddUniformNoise} {
+		if dst := noise(img, 0, false, 1); string(dst.Pix) != string(img.Pix) {
+			t.Error("expected no change for amount=0")
+		}
+
+		a := noise(img, 0.05, false, 42)
+		b := noise(img, 0.05, false, 42)
+		c := noise(img, 0.05, false, 43)
+		if a.Rect != image.Rect(0, 0, 64, 64) {
+			t.Fatalf("unexpected bounds: %v", a.Rect)
+		}
+		if string(a.Pix) != string(b.Pix) {
+			t.Error("expected the same noise for the same seed")
+		}
+		if string(a.Pix) == string(c.Pix) {
+			t.Error("expected different noise for different seeds")
+		}
+
+		var colored int
+		for i := 0; i < len(a.Pix); i += 4 {
+			if a.Pix[i+3] != 128 {
+				t.Fatal("alpha changed")
+			}
+			if a.Pix[i] != a.Pix[i+1] || a.Pix[i] != a.Pix[i+2] {
+				colored++
+			}
+		}
+		if colored == 0 {
+			t.Error("expected color noise")
+		}
+
+		mono := noise(img, 0.05, true, 42)
+		for i := 0; i < len(mono.Pix); i += 4 {
+			if mono.Pix[i] != mono.Pix[i+1] || mono.Pix[i] != mono.Pix[i+2] {
+				t.Fatalf("expected monochrome noise, got %v", mono.Pix[i:i+4])
+			}
+		}
+	}
+
+	// the standard deviation of Gaussian noise is amount
+	dst := AddNoise(img, 0.05, false, 7)
+	var sum, sq float64
+	for i, v := range dst.Pix {
+		if i%4 != 3 {
+			d := float64(v) - 128
+			sum += d
+			sq += d * d
+		}
+	}
+	n := float64(len(dst.Pix) * 3 / 4)
+	mean := sum / n
+	if std := math.Sqrt(sq/n - mean*mean); math.Abs(mean) > 0.5 || math.Abs(std-0.05*255) > 0.5 {
+		t.Errorf("unexpected distribution: mean %v, std %v", mean, std)
+	}
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"image"
+	"math"
+)
+
+// SetOpacity multiplies the alpha of every pixel of an image by a factor in [0, 1].
+// Images without alpha are treated as fully opaque.
+func SetOpacity(img image.Image, alpha float64) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	alpha = math.Max(0, math.Min(alpha, 1))
+
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = uint8(math.Floor(float64(i)*alpha + 0.5))
+	}
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y*dst.Stride + 3
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			dst.Pix[i] = lut[dst.Pix[i]]
+			i += 4
+		}
+	}
+
+	return dst
+}
+
+// Fade multiplies the alpha of an image by a linear ramp, from opacity from to opacity to,
+// along a direction at angle degrees counterclockwise from the positive x axis
+// (0° fades from left to right, 90° from bottom to top).
+//
+// The ramp spans the image: the pixels at the starting corner (or edge) are multiplied by from,
+// those at the opposite corner by to. Opacities are clamped to [0, 1].
+// Images without alpha are treated as fully opaque.
+func Fade(img image.Image, from, to float64, angle float64) *image.NRGBA {
+	dst := toNRGBA(img)
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+
+	// the y axis points down
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	dx, dy := cos, -sin
+
+	// project the corner pixels onto the direction to find the ramp's extent
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range [][2]float64{{0, 0}, {float64(w - 1), 0}, {0, float64(h - 1)}, {float64(w - 1), float64(h - 1)}} {
+		p := c[0]*dx + c[1]*dy
+		lo = math.Min(lo, p)
+		hi = math.Max(hi, p)
+	}
+
+	for y := 0; y < h; y++ {
+		i := y*dst.Stride + 3
+		for x := 0; x < w; x++ {
+			var t float64
+			if hi > lo {
+				t = (float64(x)*dx + float64(y)*dy - lo) / (hi - lo)
+			}
+			f := math.Max(0, math.Min(from+(to-from)*t, 1))
+			dst.Pix[i] = uint8(math.Floor(float64(dst.Pix[i])*f + 0.5))
+			i += 4
+		}
+	}
+
+	return dst
+}
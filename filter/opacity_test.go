@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"flag"
+	"image"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func Test_SetOpacity(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 21, 21))
+	fillRandom(img.Pix, testSeed(t))
+
+	dst := SetOpacity(img, 1)
+	if dst.Rect != image.Rect(0, 0, 16, 16) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	if string(dst.Pix) != string(img.Pix) {
+		t.Error("alpha=1 is not identity")
+	}
+
+	dst = SetOpacity(img, 0)
+	if dst.Rect != image.Rect(0, 0, 16, 16) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if dst.Pix[i] != 0 {
+			t.Fatalf("alpha=0 is not transparent at %d", i/4)
+		}
+	}
+
+	dst = SetOpacity(img, 0.5)
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if exp := (int(img.Pix[i]) + 1) / 2; int(dst.Pix[i]) != exp {
+			t.Fatalf("alpha=0.5 at %d, expected: %d, got: %d", i/4, exp, dst.Pix[i])
+		}
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, 4, 4))
+	dst = SetOpacity(gray, 0.2)
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if dst.Pix[i] != 51 {
+			t.Fatalf("gray alpha=0.2 at %d, expected: 51, got: %d", i/4, dst.Pix[i])
+		}
+	}
+}
+
+var seedFlag = flag.Int64("seed", 0, "seed for random test images (0 for a time based seed)")
+
+// testSeed returns the seed for a test's random images, and logs it,
+// so failures can be reproduced with -seed.
+func testSeed(t *testing.T) int64 {
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("seed: %d", seed)
+	return seed
+}
+
+// fillRandom fills pix deterministically from seed.
+func fillRandom(pix []uint8, seed int64) {
+	rand.New(rand.NewSource(seed)).Read(pix)
+}
+
+func Test_Fade(t *testing.T) {
+	img := image.NewGray(image.Rect(3, 4, 14, 9))
+
+	dst := Fade(img, 1, 0, 0)
+	if dst.Rect != image.Rect(0, 0, 11, 5) {
+		t.Fatalf("unexpected bounds: %v", dst.Rect)
+	}
+	for y := 0; y < 5; y++ {
+		if a := dst.NRGBAAt(0, y).A; a != 255 {
+			t.Errorf("left edge at %d: expected opaque, got %d", y, a)
+		}
+		if a := dst.NRGBAAt(10, y).A; a != 0 {
+			t.Errorf("right edge at %d: expected transparent, got %d", y, a)
+		}
+		if a := dst.NRGBAAt(5, y).A; a != 128 {
+			t.Errorf("center at %d: expected 128, got %d", y, a)
+		}
+		for x := 1; x < 11; x++ {
+			if dst.NRGBAAt(x, y).A >= dst.NRGBAAt(x-1, y).A {
+				t.Fatalf("at %dx%d: alpha doesn't decrease", x, y)
+			}
+		}
+	}
+
+	// 90° fades from bottom to top
+	dst = Fade(img, 0, 1, 90)
+	if a := dst.NRGBAAt(3, 4).A; a != 0 {
+		t.Errorf("bottom edge: expected transparent, got %d", a)
+	}
+	if a := dst.NRGBAAt(3, 0).A; a != 255 {
+		t.Errorf("top edge: expected opaque, got %d", a)
+	}
+
+	// alpha is multiplied
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	fillRandom(nrgba.Pix, testSeed(t))
+	dst = Fade(nrgba, 0.5, 0.5, 45)
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if exp := (int(nrgba.Pix[i]) + 1) / 2; int(dst.Pix[i]) != exp {
+			t.Fatalf("at %d, expected: %d, got: %d", i/4, exp, dst.Pix[i])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"image"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Pixelate replaces each block×block region of an image with its average color, in linear light.
+//
+// Blocks are aligned to the top-left corner of the image;
+// blocks at the right and bottom edges may be partial.
+func Pixelate(img image.Image, block int) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	if block <= 1 {
+		return imageutil.FromLinearFloat(src)
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	for by := 0; by < h; by += block {
+		for bx := 0; bx < w; bx += block {
+			x1, y1 := min(bx+block, w), min(by+block, h)
+
+			var sum [4]float32
+			for y := by; y < y1; y++ {
+				for x := bx; x < x1; x++ {
+					p := src.Pix[y*src.Stride+4*x:]
+					for c := range sum {
+						sum[c] += p[c]
+					}
+				}
+			}
+
+			n := float32((x1 - bx) * (y1 - by))
+			for c := range sum {
+				sum[c] /= n
+			}
+			for y := by; y < y1; y++ {
+				for x := bx; x < x1; x++ {
+					copy(src.Pix[y*src.Stride+4*x:], sum[:])
+				}
+			}
+		}
+	}
+	return imageutil.FromLinearFloat(src)
+}
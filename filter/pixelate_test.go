@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"image"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+func Test_Pixelate(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(2, 3, 25, 20))
+	fillRandom(img.Pix, testSeed(t))
+
+	const block = 5
+	dst := Pixelate(img, block)
+	if r := image.Rect(0, 0, 23, 17); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+
+	lin := imageutil.ToLinearFloat(img)
+	for by := 0; by < 17; by += block {
+		for bx := 0; bx < 23; bx += block {
+			x1, y1 := min(bx+block, 23), min(by+block, 17)
+
+			// the block average, in linear light
+			var sum [4]float32
+			for y := by; y < y1; y++ {
+				for x := bx; x < x1; x++ {
+					for c := range sum {
+						sum[c] += lin.Pix[y*lin.Stride+4*x+c]
+					}
+				}
+			}
+			exp := imageutil.NewLinearRGBA(image.Rect(0, 0, 1, 1))
+			for c := range sum {
+				exp.Pix[c] = sum[c] / float32((x1-bx)*(y1-by))
+			}
+			e := imageutil.FromLinearFloat(exp).NRGBAAt(0, 0)
+
+			for y := by; y < y1; y++ {
+				for x := bx; x < x1; x++ {
+					if c := dst.NRGBAAt(x, y); c != e {
+						t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, e, c)
+					}
+				}
+			}
+		}
+	}
+}
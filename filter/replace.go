@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+)
+
+// ReplaceColor replaces the pixels of an image that are perceptually close to a color with another color.
+//
+// Pixels within a CIE76 color difference (ΔE) of tolerance from the from color
+// are replaced by the to color (including its alpha); other pixels are unchanged.
+// A ΔE of about 2.3 is a just noticeable difference; 10 to 20 is a reasonable tolerance.
+// Alpha is ignored when comparing colors.
+// See ReplaceColorFeather to soften the edges of the replaced areas.
+func ReplaceColor(img image.Image, from, to color.Color, tolerance float64) *image.NRGBA {
+	return ReplaceColorFeather(img, from, to, tolerance, 0)
+}
+
+// ReplaceColorFeather replaces the pixels of an image that are perceptually close to a color with another color,
+// feathering the replacement by distance.
+//
+// Pixels within a ΔE of tolerance from the from color are replaced by the to color, as in ReplaceColor;
+// pixels beyond tolerance+feather are unchanged; in between, pixels are blended smoothly with the to color,
+// in linear light, with premultiplied alpha.
+func ReplaceColorFeather(img image.Image, from, to color.Color, tolerance, feather float64) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	f := color.NRGBAModel.Convert(from).(color.NRGBA)
+	t := color.NRGBAModel.Convert(to).(color.NRGBA)
+	fr, fg, fb := float64(f.R)/255, float64(f.G)/255, float64(f.B)/255
+	ta := float64(t.A) / 255
+	tl := [3]float64{
+		srgbToLinear(float64(t.R)/255) * ta,
+		srgbToLinear(float64(t.G)/255) * ta,
+		srgbToLinear(float64(t.B)/255) * ta,
+	}
+
+	// cache results, neighboring pixels often share colors
+	var last [3]uint8
+	var lastWeight float64
+	first := true
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+4 : i+4]
+			i += 4
+
+			if first || p[0] != last[0] || p[1] != last[1] || p[2] != last[2] {
+				copy(last[:], p)
+				d := deltaE(fr, fg, fb, float64(p[0])/255, float64(p[1])/255, float64(p[2])/255)
+				switch {
+				case d <= tolerance:
+					lastWeight = 1
+				case d >= tolerance+feather:
+					lastWeight = 0
+				default:
+					w := 1 - (d-tolerance)/feather
+					lastWeight = w * w * (3 - 2*w)
+				}
+				first = false
+			}
+
+			switch w := lastWeight; w {
+			case 0:
+			case 1:
+				p[0], p[1], p[2], p[3] = t.R, t.G, t.B, t.A
+			default:
+				pa := float64(p[3]) / 255
+				a := pa + w*(ta-pa)
+				if a <= 0 {
+					p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+					continue
+				}
+				for c := 0; c < 3; c++ {
+					v := srgbToLinear(float64(p[c])/255) * pa
+					p[c] = unit8(linearToSRGB((v + w*(tl[c]-v)) / a))
+				}
+				p[3] = unit8(a)
+			}
+		}
+	}
+
+	return dst
+}
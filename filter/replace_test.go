@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ReplaceColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 200, 0, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{5, 195, 5, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{200, 0, 0, 255})
+	img.SetNRGBA(3, 0, color.NRGBA{0, 120, 0, 255})
+
+	to := color.NRGBA{0, 0, 255, 128}
+	dst := ReplaceColor(img, color.NRGBA{0, 200, 0, 255}, to, 10)
+
+	if res := dst.NRGBAAt(0, 0); res != to {
+		t.Errorf("exact match not replaced: %v", res)
+	}
+	if res := dst.NRGBAAt(1, 0); res != to {
+		t.Errorf("close match not replaced: %v", res)
+	}
+	for _, x := range []int{2, 3} {
+		if exp, res := img.NRGBAAt(x, 0), dst.NRGBAAt(x, 0); exp != res {
+			t.Errorf("at %d, expected: %v, got: %v", x, exp, res)
+		}
+	}
+}
+
+func Test_ReplaceColorFeather(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 200, 0, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{5, 195, 5, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{0, 170, 0, 255})
+	img.SetNRGBA(3, 0, color.NRGBA{200, 0, 0, 255})
+
+	from := color.NRGBA{0, 200, 0, 255}
+	to := color.NRGBA{0, 0, 255, 255}
+	d := deltaE(0, 200.0/255, 0, 0, 170.0/255, 0)
+	dst := ReplaceColorFeather(img, from, to, 5, 2*(d-5))
+
+	if res := dst.NRGBAAt(0, 0); res != to {
+		t.Errorf("exact match not replaced: %v", res)
+	}
+	if res := dst.NRGBAAt(3, 0); res != img.NRGBAAt(3, 0) {
+		t.Errorf("far color changed: %v", res)
+	}
+
+	// halfway through the feather, blended halfway in linear light
+	res := dst.NRGBAAt(2, 0)
+	exp := color.NRGBA{
+		0,
+		unit8(linearToSRGB(srgbToLinear(170.0/255) / 2)),
+		unit8(linearToSRGB(0.5)),
+		255,
+	}
+	if res != exp {
+		t.Errorf("expected: %v, got: %v", exp, res)
+	}
+
+	// without feathering, the same as ReplaceColor
+	hard := ReplaceColor(img, from, to, 5)
+	if soft := ReplaceColorFeather(img, from, to, 5, 0); string(soft.Pix) != string(hard.Pix) {
+		t.Errorf("expected: %v, got: %v", hard.Pix, soft.Pix)
+	}
+}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"image"
+	"math"
+)
+
+// SelectiveColor adjusts the saturation and lightness of the pixels of an image within a hue band.
+//
+// The band is centered on targetHue, in degrees, and pixels within hueWidth/2 degrees of it
+// are fully adjusted; the adjustment then falls off smoothly, reaching zero at hueWidth degrees.
+// The adjustment is also weighted by the pixel's saturation, so grays are left unchanged.
+//
+// Shifts are in [-1, 1]: positive values move saturation (or lightness) toward 1,
+// negative values toward 0, proportionally.
+// Colors are handled as HSL, on sRGB encoded values.
+func SelectiveColor(img image.Image, targetHue, hueWidth, satShift, lightShift float64) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	hueWidth = math.Abs(hueWidth)
+	satShift = math.Max(-1, math.Min(satShift, 1))
+	lightShift = math.Max(-1, math.Min(lightShift, 1))
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+3 : i+3]
+			i += 4
+
+			h, s, l := rgbToHSL(float64(p[0])/255, float64(p[1])/255, float64(p[2])/255)
+			w := s * hueWeight(h, targetHue, hueWidth)
+			if w == 0 {
+				continue
+			}
+
+			s = shift(s, w*satShift)
+			l = shift(l, w*lightShift)
+			r, g, b := hslToRGB(h, s, l)
+			p[0] = unit8(r)
+			p[1] = unit8(g)
+			p[2] = unit8(b)
+		}
+	}
+
+	return dst
+}
+
+// hueWeight is 1 within width/2 degrees of target, falling off smoothly to 0 at width degrees.
+func hueWeight(hue, target, width float64) float64 {
+	d := math.Abs(math.Mod(hue-target, 360))
+	if d > 180 {
+		d = 360 - d
+	}
+	switch {
+	case d <= width/2:
+		return 1
+	case d >= width:
+		return 0
+	}
+	t := (d - width/2) / (width / 2)
+	return 1 - t*t*(3-2*t)
+}
+
+// shift moves v in [0, 1] toward 1 (amount > 0) or toward 0 (amount < 0), proportionally.
+func shift(v, amount float64) float64 {
+	if amount > 0 {
+		return v + (1-v)*amount
+	}
+	return v + v*amount
+}
+
+// unit8 converts a value in [0, 1] to a rounded, clamped, 8-bit value.
+func unit8(v float64) uint8 {
+	return uint8(math.Floor(255*math.Max(0, math.Min(v, 1)) + 0.5))
+}
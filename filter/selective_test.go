@@ -0,0 +1,40 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_SelectiveColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 6, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{200, 50, 50, 255})  // red
+	img.SetNRGBA(1, 0, color.NRGBA{200, 110, 50, 255}) // orange, 36°
+	img.SetNRGBA(2, 0, color.NRGBA{50, 200, 50, 255})  // green
+	img.SetNRGBA(3, 0, color.NRGBA{50, 50, 200, 255})  // blue
+	img.SetNRGBA(4, 0, color.NRGBA{128, 128, 128, 255})
+	img.SetNRGBA(5, 0, color.NRGBA{200, 50, 60, 128}) // red, 356°
+
+	dst := SelectiveColor(img, 0, 30, 0.5, 0)
+
+	for _, x := range []int{2, 3, 4} {
+		if exp, res := img.NRGBAAt(x, 0), dst.NRGBAAt(x, 0); exp != res {
+			t.Errorf("at %d, expected: %v, got: %v", x, exp, res)
+		}
+	}
+	for _, x := range []int{0, 5} {
+		_, s0, l0 := rgbToHSL(float64(img.Pix[4*x])/255, float64(img.Pix[4*x+1])/255, float64(img.Pix[4*x+2])/255)
+		_, s1, l1 := rgbToHSL(float64(dst.Pix[4*x])/255, float64(dst.Pix[4*x+1])/255, float64(dst.Pix[4*x+2])/255)
+		if s1 <= s0 || l1-l0 > 0.01 || l0-l1 > 0.01 || dst.Pix[4*x+3] != img.Pix[4*x+3] {
+			t.Errorf("at %d, not saturated: %v, %v", x, img.NRGBAAt(x, 0), dst.NRGBAAt(x, 0))
+		}
+	}
+
+	// the edge of the band is partially adjusted
+	r0 := img.NRGBAAt(1, 0)
+	r1 := dst.NRGBAAt(1, 0)
+	full := SelectiveColor(img, 36, 30, 0.5, 0).NRGBAAt(1, 0)
+	if r1 == r0 || r1 == full {
+		t.Errorf("band edge, unexpected: %v, %v, %v", r0, r1, full)
+	}
+}
@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// DropShadow adds a drop shadow behind an image.
+//
+// The shadow is the alpha channel of the image, moved by offset,
+// blurred by a Gaussian of standard deviation blur (in pixels), and colored with shadowColor.
+// The image is composited over its shadow, in linear light.
+// The canvas is expanded to fit the shadow, and the result is anchored at the origin.
+func DropShadow(img image.Image, offset image.Point, blur float64, shadowColor color.Color) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	blur = math.Max(0, blur)
+	radius := int(math.Ceil(3 * blur))
+
+	// canvas, relative to the image
+	canvas := src.Rect
+	shadow := canvas.Add(offset).Inset(-radius)
+	canvas = canvas.Union(shadow)
+	w, h := canvas.Dx(), canvas.Dy()
+
+	mask := make([]float32, w*h)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			i := (y+offset.Y-canvas.Min.Y)*w + x + offset.X - canvas.Min.X
+			mask[i] = src.Pix[y*src.Stride+4*x+3]
+		}
+	}
+	mask = gaussianBlur(mask, w, h, 1, blur)
+
+	return imageutil.FromLinearFloat(overMask(src, mask, canvas, shadowColor))
+}
+
+// overMask composites an image over a mask colored with c, in linear light.
+// The mask covers the canvas, given relative to the image.
+func overMask(src *imageutil.LinearRGBA, mask []float32, canvas image.Rectangle, c color.Color) *imageutil.LinearRGBA {
+	w, h := canvas.Dx(), canvas.Dy()
+
+	// mask color, straight, in linear light
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	mr := float32(imageutil.SRGB16ToLinear(n.R)) / 65535
+	mg := float32(imageutil.SRGB16ToLinear(n.G)) / 65535
+	mb := float32(imageutil.SRGB16ToLinear(n.B)) / 65535
+	ma := float32(n.A) / 65535
+
+	dst := imageutil.NewLinearRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := ma * mask[y*w+x]
+			d := dst.Pix[y*dst.Stride+4*x:]
+			d[0], d[1], d[2], d[3] = mr*a, mg*a, mb*a, a
+
+			// source over mask
+			sx, sy := x+canvas.Min.X, y+canvas.Min.Y
+			if (image.Point{sx, sy}).In(src.Rect) {
+				s := src.Pix[sy*src.Stride+4*sx:]
+				f := 1 - s[3]
+				for i := range 4 {
+					d[i] = s[i] + f*d[i]
+				}
+			}
+		}
+	}
+	return dst
+}
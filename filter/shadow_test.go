@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_DropShadow(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 10, 30, 30))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{255, 0, 0, 255})
+	}
+
+	dst := DropShadow(img, image.Pt(5, 5), 2, color.Black)
+
+	// blur radius 6: shadow spans -1 to 31, relative to the image
+	if r := image.Rect(0, 0, 32, 32); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+
+	at := func(x, y int) color.NRGBA { return dst.NRGBAAt(x+1, y+1) }
+
+	// the image is on top
+	if c := at(0, 0); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("unexpected image pixel: %v", c)
+	}
+	if c := at(19, 19); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("unexpected image pixel: %v", c)
+	}
+
+	// the shadow is solid well inside, soft at its edges, and fades out
+	if c := at(20, 15); c.R != 0 || c.A < 250 {
+		t.Errorf("unexpected shadow pixel: %v", c)
+	}
+	if c := at(25, 15); c.A < 100 || c.A > 155 {
+		t.Errorf("unexpected shadow edge: %v", c)
+	}
+	if c := at(28, 15); c.A > 30 {
+		t.Errorf("unexpected shadow fade: %v", c)
+	}
+	if c := at(-1, 15); c.A > 2 {
+		t.Errorf("unexpected shadow on the left: %v", c)
+	}
+	if c := at(30, 30); c.A != 0 {
+		t.Errorf("unexpected shadow in the corner: %v", c)
+	}
+	for x := 20; x < 28; x++ {
+		if at(x, 15).A < at(x+1, 15).A {
+			t.Errorf("shadow doesn't fade at %d", x)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package filter
+
+import (
+	"image"
+)
+
+// Solarize inverts the color channel values of an image that are above threshold,
+// like the darkroom effect of overexposing a print.
+//
+// Each channel is mapped through the tone curve independently, on sRGB encoded 8-bit values:
+// values up to threshold are unchanged, values above it become 255-v.
+// Alpha is preserved.
+func Solarize(img image.Image, threshold uint8) *image.NRGBA {
+	var lut [256]uint8
+	for i := range lut {
+		lut[i] = uint8(i)
+		if i > int(threshold) {
+			lut[i] = 255 - uint8(i)
+		}
+	}
+
+	dst := toNRGBA(img)
+	applyLUT(dst, &lut, &lut, &lut)
+	return dst
+}
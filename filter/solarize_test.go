@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Solarize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+	for i := 0; i < 256; i++ {
+		img.SetNRGBA(i, 0, color.NRGBA{uint8(i), uint8(255 - i), 100, 77})
+	}
+
+	dst := Solarize(img, 128)
+	for i := 0; i < 256; i++ {
+		s := img.NRGBAAt(i, 0)
+		d := dst.NRGBAAt(i, 0)
+		for c, v := range []uint8{s.R, s.G, s.B} {
+			exp := v
+			if v > 128 {
+				exp = 255 - v
+			}
+			if got := []uint8{d.R, d.G, d.B}[c]; got != exp {
+				t.Fatalf("channel %d: expected %d for %d, got %d", c, exp, v, got)
+			}
+		}
+		if d.A != 77 {
+			t.Fatalf("alpha changed: %v", d)
+		}
+	}
+
+	// with a threshold of 255 nothing changes
+	if dst := Solarize(img, 255); string(dst.Pix) != string(img.Pix) {
+		t.Error("expected no change")
+	}
+}
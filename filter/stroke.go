@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Stroke outlines the shape of an image, as given by its alpha channel, sticker-style.
+//
+// The stroke extends width pixels beyond the edge of the shape, and is antialiased.
+// The image is composited over its stroke, in linear light.
+// The canvas is expanded by width pixels on each side, and the result is anchored at the origin.
+func Stroke(img image.Image, width int, strokeColor color.Color) *image.NRGBA {
+	src := imageutil.ToLinearFloat(img)
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	width = max(0, width)
+
+	// canvas, relative to the image
+	canvas := src.Rect.Inset(-width)
+	w, h := canvas.Dx(), canvas.Dy()
+
+	// dilate alpha with an antialiased disc
+	type tap struct {
+		dx, dy int
+		f      float32
+	}
+	var disc []tap
+	for dy := -width; dy <= width; dy++ {
+		for dx := -width; dx <= width; dx++ {
+			d := math.Hypot(float64(dx), float64(dy))
+			if f := math.Min(float64(width)+1-d, 1); f > 0 {
+				disc = append(disc, tap{dx, dy, float32(f)})
+			}
+		}
+	}
+
+	mask := make([]float32, w*h)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			a := src.Pix[y*src.Stride+4*x+3]
+			if a == 0 {
+				continue
+			}
+			for _, t := range disc {
+				i := (y+width+t.dy)*w + x + width + t.dx
+				mask[i] = max(mask[i], a*t.f)
+			}
+		}
+	}
+
+	return imageutil.FromLinearFloat(overMask(src, mask, canvas, strokeColor))
+}
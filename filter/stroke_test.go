@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_Stroke(t *testing.T) {
+	// a filled disc of radius 10, on a transparent 30x30 canvas
+	img := image.NewNRGBA(image.Rect(0, 0, 30, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			if math.Hypot(float64(x)-14.5, float64(y)-14.5) < 10 {
+				img.SetNRGBA(x, y, color.NRGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	const width = 3
+	dst := Stroke(img, width, color.White)
+	if r := image.Rect(0, 0, 36, 36); dst.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+	}
+
+	for y := 0; y < 36; y++ {
+		for x := 0; x < 36; x++ {
+			d := math.Hypot(float64(x)-17.5, float64(y)-17.5)
+			c := dst.NRGBAAt(x, y)
+			switch {
+			case d < 9.5: // the disc
+				if c != (color.NRGBA{0, 0, 255, 255}) {
+					t.Errorf("at %dx%d, unexpected disc: %v", x, y, c)
+				}
+			case d > 10.5 && d < 9.5+width: // the ring
+				if c != (color.NRGBA{255, 255, 255, 255}) {
+					t.Errorf("at %dx%d, unexpected ring: %v", x, y, c)
+				}
+			case d > 11+width: // outside
+				if c.A != 0 {
+					t.Errorf("at %dx%d, unexpected outside: %v", x, y, c)
+				}
+			}
+		}
+	}
+
+	// antialiased: partial coverage at the outer edge
+	var partial int
+	for i := 3; i < len(dst.Pix); i += 4 {
+		if a := dst.Pix[i]; a != 0 && a != 255 {
+			partial++
+		}
+	}
+	if partial == 0 {
+		t.Error("stroke is not antialiased")
+	}
+}
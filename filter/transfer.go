@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"image"
+	"math"
+)
+
+// ColorTransfer transfers the color statistics of reference to src, using the method of Reinhard et al.
+//
+// Both images are converted to CIE L*a*b*, and each channel of src is shifted and scaled
+// to match the mean and standard deviation of the corresponding channel of reference.
+// Fully transparent pixels are ignored.
+func ColorTransfer(src, reference image.Image) *image.NRGBA {
+	dst := toNRGBA(src)
+	ref := toNRGBA(reference)
+
+	lab := labPixels(dst)
+	smean, sstd := labStats(lab)
+	rmean, rstd := labStats(labPixels(ref))
+
+	var scale [3]float64
+	for c := range scale {
+		if sstd[c] > 0 {
+			scale[c] = rstd[c] / sstd[c]
+		} else {
+			scale[c] = 1
+		}
+	}
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			p := dst.Pix[i : i+4 : i+4]
+			if v := lab[y*dst.Rect.Dx()+x]; p[3] != 0 {
+				for c := range v {
+					v[c] = (v[c]-smean[c])*scale[c] + rmean[c]
+				}
+				r, g, b := labToRGB(v[0], v[1], v[2])
+				p[0], p[1], p[2] = unit8(r), unit8(g), unit8(b)
+			}
+			i += 4
+		}
+	}
+	return dst
+}
+
+// labPixels converts the pixels of an image to CIE L*a*b*, in row-major order.
+// Fully transparent pixels are NaN.
+func labPixels(img *image.NRGBA) [][3]float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	res := make([][3]float64, 0, w*h)
+	for y := 0; y < h; y++ {
+		i := y * img.Stride
+		for x := 0; x < w; x++ {
+			p := img.Pix[i : i+4 : i+4]
+			if p[3] == 0 {
+				res = append(res, [3]float64{math.NaN(), math.NaN(), math.NaN()})
+			} else {
+				l, a, b := rgbToLab(float64(p[0])/255, float64(p[1])/255, float64(p[2])/255)
+				res = append(res, [3]float64{l, a, b})
+			}
+			i += 4
+		}
+	}
+	return res
+}
+
+// labStats computes the mean and standard deviation of each channel, ignoring NaN.
+func labStats(lab [][3]float64) (mean, std [3]float64) {
+	var n float64
+	for _, v := range lab {
+		if math.IsNaN(v[0]) {
+			continue
+		}
+		for c := range v {
+			mean[c] += v[c]
+		}
+		n++
+	}
+	if n == 0 {
+		return mean, std
+	}
+	for c := range mean {
+		mean[c] /= n
+	}
+	for _, v := range lab {
+		if math.IsNaN(v[0]) {
+			continue
+		}
+		for c := range v {
+			std[c] += (v[c] - mean[c]) * (v[c] - mean[c])
+		}
+	}
+	for c := range std {
+		std[c] = math.Sqrt(std[c] / n)
+	}
+	return mean, std
+}
@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_ColorTransfer(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	fillRandom(img.Pix, testSeed(t))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	// transferring an image's statistics onto itself is near the identity
+	dst := ColorTransfer(img, img)
+	for i, p := range dst.Pix {
+		if abs(int(p)-int(img.Pix[i])) > 1 {
+			t.Fatalf("self transfer is not near the identity: %d, %d", p, img.Pix[i])
+		}
+	}
+
+	// transferring a bluish reference makes a gray image bluish
+	gray := image.NewNRGBA(img.Rect)
+	blue := image.NewNRGBA(img.Rect)
+	for i := 0; i < len(img.Pix); i += 4 {
+		v := img.Pix[i]
+		copy(gray.Pix[i:], []uint8{v, v, v, 255})
+		copy(blue.Pix[i:], []uint8{v / 4, v / 3, v, 255})
+	}
+	dst = ColorTransfer(gray, blue)
+
+	var r, b int
+	for i := 0; i < len(dst.Pix); i += 4 {
+		r += int(dst.Pix[i])
+		b += int(dst.Pix[i+2])
+	}
+	if b <= 2*r {
+		t.Errorf("not bluish: %d, %d", r, b)
+	}
+}
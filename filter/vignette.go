@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// Vignette darkens an image toward its corners.
+// Darkening begins halfway between the center and the corners.
+// See VignetteRadius.
+func Vignette(img image.Image, strength float64) *image.NRGBA {
+	return VignetteRadius(img, 0.5, strength)
+}
+
+// VignetteRadius darkens an image toward its corners.
+//
+// Radius is the distance from the center where darkening begins,
+// as a fraction of the distance to the corners, in [0, 1).
+// Strength is how much corners are darkened, in [0, 1]:
+// corner pixels are scaled by 1-strength in linear light.
+func VignetteRadius(img image.Image, radius, strength float64) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	radius = math.Max(0, math.Min(radius, 1))
+	strength = math.Max(0, math.Min(strength, 1))
+
+	cx := float64(dst.Rect.Dx()-1) / 2
+	cy := float64(dst.Rect.Dy()-1) / 2
+	max := math.Hypot(cx, cy)
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		i := y * dst.Stride
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var d float64
+			if max > 0 {
+				d = math.Hypot(float64(x)-cx, float64(y)-cy) / max
+			}
+			if d > radius {
+				t := (d - radius) / (1 - radius)
+				f := 1 - strength*t*t*(3-2*t)
+				p := dst.Pix[i : i+3 : i+3]
+				p[0] = scaleLinear(p[0], f)
+				p[1] = scaleLinear(p[1], f)
+				p[2] = scaleLinear(p[2], f)
+			}
+			i += 4
+		}
+	}
+
+	return dst
+}
+
+// scales an 8-bit sRGB value by f in linear light
+func scaleLinear(srgb uint8, f float64) uint8 {
+	lin := float64(imageutil.SRGB8ToLinear(srgb)) * f
+	return imageutil.LinearToSRGB8(uint16(math.Max(0, math.Min(lin+0.5, 65535))))
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+func Test_Vignette(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 10, 31, 41))
+	draw.Draw(img, img.Rect, image.NewUniform(color.NRGBA{200, 150, 100, 255}), image.ZP, draw.Src)
+
+	for _, strength := range []float64{0, 0.25, 0.5, 1} {
+		dst := Vignette(img, strength)
+
+		if dst.Rect != image.Rect(0, 0, 21, 31) {
+			t.Fatalf("unexpected bounds: %v", dst.Rect)
+		}
+		if res := dst.NRGBAAt(10, 15); res != img.NRGBAAt(20, 25) {
+			t.Errorf("strength %v: center changed to %v", strength, res)
+		}
+
+		exp := color.NRGBA{
+			scaleLinear(200, 1-strength),
+			scaleLinear(150, 1-strength),
+			scaleLinear(100, 1-strength),
+			255,
+		}
+		for _, pt := range []image.Point{{0, 0}, {20, 0}, {0, 30}, {20, 30}} {
+			if res := dst.NRGBAAt(pt.X, pt.Y); res != exp {
+				t.Errorf("strength %v: at %v, expected: %v, got: %v", strength, pt, exp, res)
+			}
+		}
+	}
+
+	// corner at half strength is half as bright in linear light
+	dst := Vignette(img, 0.5)
+	exp := imageutil.SRGB8ToLinear(200) / 2
+	res := imageutil.SRGB8ToLinear(dst.Pix[0])
+	if d := int(exp) - int(res); d < -400 || d > 400 {
+		t.Errorf("corner linear value, expected: %d, got: %d", exp, res)
+	}
+}
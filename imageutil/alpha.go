@@ -0,0 +1,66 @@
+package imageutil
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+)
+
+// AlphaChannel extracts the alpha channel of an image, as a grayscale image anchored at the origin.
+// Images without alpha are fully opaque (255).
+func AlphaChannel(img image.Image) *image.Gray {
+	bounds := img.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	switch src := img.(type) {
+	case *image.NRGBA:
+		alphaPlane(dst, src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride)
+	case *image.RGBA:
+		alphaPlane(dst, src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride)
+	case *image.NYCbCrA:
+		resample(dst.Pix, dst.Stride, src.A[src.AOffset(bounds.Min.X, bounds.Min.Y):], src.AStride, bounds.Dy())
+	default:
+		alpha := &image.Alpha{Pix: dst.Pix, Stride: dst.Stride, Rect: dst.Rect}
+		draw.Draw(alpha, alpha.Rect, img, bounds.Min, draw.Src)
+	}
+	return dst
+}
+
+// SetAlpha replaces the alpha channel of an image with a mask, returning an NRGBA image anchored at the origin.
+// Colors are preserved, except for those of fully transparent source pixels, which are unknown.
+// The mask must have the same size as the image.
+func SetAlpha(img image.Image, mask *image.Gray) (*image.NRGBA, error) {
+	bounds := img.Bounds()
+	if bounds.Size() != mask.Rect.Size() {
+		return nil, errors.New("imageutil: mask size doesn't match image")
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+
+	var dst_row, src_row int
+	src_row = mask.PixOffset(mask.Rect.Min.X, mask.Rect.Min.Y)
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		d := dst.Pix[dst_row:]
+		s := mask.Pix[src_row : src_row+dst.Rect.Dx()]
+		for x, a := range s {
+			d[4*x+3] = a
+		}
+		dst_row += dst.Stride
+		src_row += mask.Stride
+	}
+	return dst, nil
+}
+
+func alphaPlane(dst *image.Gray, src []uint8, src_stride int) {
+	var dst_row, src_row int
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		d := dst.Pix[dst_row : dst_row+dst.Rect.Dx()]
+		s := src[src_row:]
+		for x := range d {
+			d[x] = s[4*x+3]
+		}
+		dst_row += dst.Stride
+		src_row += src_stride
+	}
+}
@@ -0,0 +1,110 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_AlphaChannel(t *testing.T) {
+	nrgba := image.NewNRGBA(image.Rect(2, 3, 258, 13))
+	for y := 3; y < 13; y++ {
+		for x := 2; x < 258; x++ {
+			nrgba.SetNRGBA(x, y, color.NRGBA{uint8(y), 255, uint8(x), uint8(x - 2)})
+		}
+	}
+	nycbcra := image.NewNYCbCrA(nrgba.Rect, image.YCbCrSubsampleRatio420)
+	for i := range nycbcra.A {
+		nycbcra.A[i] = uint8(i)
+	}
+
+	tests := []image.Image{
+		nrgba,
+		nrgba.SubImage(image.Rect(5, 4, 200, 12)),
+		ToRGBAPremultiplied(nrgba),
+		nycbcra,
+		nycbcra.SubImage(image.Rect(5, 4, 200, 12)),
+		image.NewNRGBA64(image.Rect(0, 0, 5, 5)),
+		image.NewGray(image.Rect(0, 0, 5, 5)),
+		&image.Uniform{color.NRGBA{1, 2, 3, 4}},
+	}
+	for _, img := range tests {
+		bounds := img.Bounds()
+		if bounds.Dx() > 1000 {
+			bounds = image.Rect(0, 0, 3, 3)
+			img = bounded{img, bounds}
+		}
+
+		dst := AlphaChannel(img)
+		if r := image.Rect(0, 0, bounds.Dx(), bounds.Dy()); dst.Rect != r {
+			t.Fatalf("%T: expected: %v, got: %v", img, r, dst.Rect)
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				_, _, _, a := img.At(x, y).RGBA()
+				exp := uint8(a >> 8)
+				if res := dst.GrayAt(x-bounds.Min.X, y-bounds.Min.Y).Y; exp != res {
+					t.Fatalf("%T: at %dx%d, expected: %d, got: %d", img, x, y, exp, res)
+				}
+			}
+		}
+	}
+}
+
+// bounded restricts the bounds of an infinite image.
+type bounded struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (u bounded) Bounds() image.Rectangle { return u.rect }
+
+func Test_SetAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(2, 3, 18, 13))
+	for y := 3; y < 13; y++ {
+		for x := 2; x < 18; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(16 * y), uint8(x * x), uint8(x ^ y), uint8(128 + x)})
+		}
+	}
+	mask := image.NewGray(image.Rect(0, 0, 16, 10))
+	for i := range mask.Pix {
+		mask.Pix[i] = uint8(3 * i)
+	}
+
+	dst, err := SetAlpha(img, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 16; x++ {
+			src := img.NRGBAAt(x+2, y+3)
+			exp := color.NRGBA{src.R, src.G, src.B, mask.GrayAt(x, y).Y}
+			if res := dst.NRGBAAt(x, y); exp != res {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, exp, res)
+			}
+		}
+	}
+
+	// opaque sources
+	gray := image.NewGray(image.Rect(0, 0, 16, 10))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(255 - i)
+	}
+	dst, err = SetAlpha(gray, mask)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 16; x++ {
+			v := gray.GrayAt(x, y).Y
+			exp := color.NRGBA{v, v, v, mask.GrayAt(x, y).Y}
+			if res := dst.NRGBAAt(x, y); exp != res {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, exp, res)
+			}
+		}
+	}
+
+	if _, err := SetAlpha(img, image.NewGray(image.Rect(0, 0, 10, 16))); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,22 @@
+package imageutil
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Canonical converts an image to an RGBA image anchored at the origin,
+// with a tightly packed stride (Stride == 4*width).
+// The returned image is always a copy, even if src is already canonical.
+func Canonical(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	if src, ok := src.(*image.RGBA); ok {
+		resample(dst.Pix, dst.Stride, src.Pix[src.PixOffset(bounds.Min.X, bounds.Min.Y):], src.Stride, bounds.Dy())
+		return dst
+	}
+
+	draw.Draw(dst, dst.Rect, src, bounds.Min, draw.Src)
+	return dst
+}
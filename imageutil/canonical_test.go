@@ -0,0 +1,70 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Canonical(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	rect := image.Rect(0, 0, 16, 16)
+
+	testSub := func(img image.Image) {
+		dst := Canonical(img)
+
+		bounds := img.Bounds()
+		if dst.Rect != image.Rect(0, 0, bounds.Dx(), bounds.Dy()) {
+			t.Errorf("%T: bounds not at origin: %v", img, dst.Rect)
+		}
+		if dst.Stride != 4*bounds.Dx() {
+			t.Errorf("%T: stride not tightly packed: %d", img, dst.Stride)
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				exp := color.RGBAModel.Convert(img.At(x, y))
+				res := dst.At(x-bounds.Min.X, y-bounds.Min.Y)
+				if exp != res {
+					t.Errorf("%T: colors don't match at %2dx%d", img, x, y)
+					return
+				}
+			}
+		}
+	}
+
+	testImg := func(img imageWithSubImage) {
+		testSub(img)
+		testSub(img.SubImage(image.Rect(1, 1, 16, 16)))
+		testSub(img.SubImage(image.Rect(2, 3, 14, 13)))
+	}
+
+	{
+		img := image.NewRGBA(rect)
+		fillRandom(img.Pix, next())
+		testImg(img)
+
+		dst := Canonical(img)
+		if &dst.Pix[0] == &img.Pix[0] {
+			t.Error("*image.RGBA: expected a copy")
+		}
+	}
+	{
+		img := image.NewNRGBA(rect)
+		fillRandom(img.Pix, next())
+		testImg(img)
+	}
+	{
+		img := image.NewGray(rect)
+		fillRandom(img.Pix, next())
+		testImg(img)
+	}
+	{
+		img := image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+		fillRandom(img.Y, next())
+		fillRandom(img.Cb, next())
+		fillRandom(img.Cr, next())
+		testImg(img)
+	}
+}
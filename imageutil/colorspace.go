@@ -0,0 +1,128 @@
+package imageutil
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ColorSpace identifies how the color values of an image are to be interpreted.
+type ColorSpace int
+
+const (
+	// SRGB is gamma encoded sRGB, the default for untagged images.
+	SRGB ColorSpace = iota
+	// Linear is sRGB, with linear light (not gamma encoded) values.
+	Linear
+	// DisplayP3 is gamma encoded Display P3.
+	DisplayP3
+	// AdobeRGB is gamma encoded Adobe RGB (1998).
+	AdobeRGB
+)
+
+// TaggedImage is an image tagged with its ColorSpace,
+// so later processing steps know how to interpret its values.
+//
+// At returns the colors of the wrapped image as is: values in Space, not converted to sRGB
+// (unlike a LinearRGBA, whose At converts to sRGB).
+// Use Delinearize to get an image whose colors are sRGB.
+type TaggedImage struct {
+	image.Image
+	Space ColorSpace
+}
+
+// SpaceOf returns the ColorSpace of an image:
+// the tag of a TaggedImage, Linear for a LinearRGBA, and SRGB for other images.
+func SpaceOf(img image.Image) ColorSpace {
+	switch img := img.(type) {
+	case TaggedImage:
+		return img.Space
+	case *TaggedImage:
+		return img.Space
+	case *LinearRGBA:
+		return Linear
+	}
+	return SRGB
+}
+
+// Linearize converts an image to linear light sRGB, stored in an NRGBA64 image.
+//
+// Images that are already Linear are returned without conversion (tagged, if not already).
+// Display P3 and Adobe RGB images are converted to sRGB primaries, clipping colors out of the sRGB gamut
+// (see DisplayP3ToSRGB for other gamut mappings).
+func Linearize(img image.Image) TaggedImage {
+	switch space := SpaceOf(img); space {
+	case Linear:
+		return tagged(img, Linear)
+	case DisplayP3, AdobeRGB:
+		return TaggedImage{wideToSRGB(img, space, Clip, true), Linear}
+	case SRGB:
+		dst := toNRGBA64(img)
+		for i := 0; i < len(dst.Pix); i += 8 {
+			for c := i; c < i+6; c += 2 {
+				v := SRGB16ToLinear(uint16(dst.Pix[c])<<8 | uint16(dst.Pix[c+1]))
+				dst.Pix[c], dst.Pix[c+1] = uint8(v>>8), uint8(v)
+			}
+		}
+		return TaggedImage{dst, Linear}
+	}
+	panic("Unsupported color space")
+}
+
+// Delinearize converts an image to sRGB, stored in an NRGBA64 image
+// (an NRGBA image, for a LinearRGBA).
+//
+// Images that are already SRGB (including untagged images) are returned without conversion, tagged.
+// Display P3 and Adobe RGB images are converted to sRGB primaries, clipping colors out of the sRGB gamut
+// (see DisplayP3ToSRGB for other gamut mappings).
+func Delinearize(img image.Image) TaggedImage {
+	switch space := SpaceOf(img); space {
+	case SRGB:
+		return tagged(img, SRGB)
+	case DisplayP3, AdobeRGB:
+		return TaggedImage{wideToSRGB(img, space, Clip, false), SRGB}
+	case Linear:
+		if l, ok := untag(img).(*LinearRGBA); ok {
+			return TaggedImage{FromLinearFloat(l), SRGB}
+		}
+		dst := toNRGBA64(img)
+		for i := 0; i < len(dst.Pix); i += 8 {
+			for c := i; c < i+6; c += 2 {
+				v := LinearToSRGB16(uint16(dst.Pix[c])<<8 | uint16(dst.Pix[c+1]))
+				dst.Pix[c], dst.Pix[c+1] = uint8(v>>8), uint8(v)
+			}
+		}
+		return TaggedImage{dst, SRGB}
+	}
+	panic("Unsupported color space")
+}
+
+// tagged tags an image, unless it is already a TaggedImage.
+func tagged(img image.Image, space ColorSpace) TaggedImage {
+	switch img := img.(type) {
+	case TaggedImage:
+		return img
+	case *TaggedImage:
+		return *img
+	}
+	return TaggedImage{img, space}
+}
+
+// untag returns the image wrapped by a TaggedImage.
+func untag(img image.Image) image.Image {
+	switch t := img.(type) {
+	case TaggedImage:
+		return t.Image
+	case *TaggedImage:
+		return t.Image
+	}
+	return img
+}
+
+// toNRGBA64 copies the values of an image, untagged, into an NRGBA64 image anchored at the origin.
+func toNRGBA64(img image.Image) *image.NRGBA64 {
+	img = untag(img)
+	bounds := img.Bounds()
+	dst := image.NewNRGBA64(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,134 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+func Test_ColorSpace(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(1, 2, 9, 7))
+	fillRandom(img.Pix, testSeed(t))
+
+	if s := SpaceOf(img); s != SRGB {
+		t.Errorf("expected SRGB, got %d", s)
+	}
+
+	lin := Linearize(img)
+	if lin.Space != Linear || SpaceOf(lin) != Linear || SpaceOf(&lin) != Linear {
+		t.Fatalf("expected Linear, got %d", lin.Space)
+	}
+	if lin.Bounds() != image.Rect(0, 0, 8, 5) {
+		t.Fatalf("unexpected bounds: %v", lin.Bounds())
+	}
+
+	// redundant conversions are skipped
+	if again := Linearize(lin); again.Image != lin.Image {
+		t.Error("linearized twice")
+	}
+	if again := Linearize(&lin); again.Image != lin.Image {
+		t.Error("linearized twice")
+	}
+	if srgb := Delinearize(img); srgb.Image != image.Image(img) || srgb.Space != SRGB {
+		t.Error("delinearized an sRGB image")
+	}
+	if l := ToLinearFloat(img); Linearize(l).Image != image.Image(l) {
+		t.Error("linearized a LinearRGBA")
+	}
+
+	// ToLinearFloat respects the tag: it doesn't linearize again
+	want := ToLinearFloat(img)
+	got := ToLinearFloat(lin)
+	for i := range want.Pix {
+		if d := want.Pix[i] - got.Pix[i]; d > 1e-4 || d < -1e-4 {
+			t.Fatalf("at %d: expected %f, got %f", i, want.Pix[i], got.Pix[i])
+		}
+	}
+	if got := ToLinearFloat(TaggedImage{want, Linear}); !slices.Equal(got.Pix, want.Pix) {
+		t.Error("tagged LinearRGBA was not copied as is")
+	}
+
+	// round trip
+	back := Delinearize(lin)
+	if back.Space != SRGB {
+		t.Fatalf("expected SRGB, got %d", back.Space)
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 8; x++ {
+			r0, g0, b0, a0 := img.At(x+1, y+2).RGBA()
+			r1, g1, b1, a1 := back.At(x, y).RGBA()
+			if abs(int(r0)-int(r1)) > 256 || abs(int(g0)-int(g1)) > 256 || abs(int(b0)-int(b1)) > 256 || a0 != a1 {
+				t.Fatalf("at %dx%d: colors don't match", x, y)
+			}
+		}
+	}
+	if back := Delinearize(TaggedImage{want, Linear}); back.Bounds() != image.Rect(0, 0, 8, 5) {
+		t.Errorf("unexpected bounds: %v", back.Bounds())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unsupported color space")
+		}
+	}()
+	Linearize(TaggedImage{img, ColorSpace(-1)})
+}
+
+func Test_ColorSpaceWide(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA64(0, 0, color.NRGBA64{0xffff, 0xffff, 0xffff, 0xffff}) // white
+	img.SetNRGBA64(1, 0, color.NRGBA64{0x8000, 0x8000, 0x8000, 0x8000}) // gray
+	img.SetNRGBA64(2, 0, color.NRGBA64{0, 0xffff, 0, 0xffff})           // green
+
+	for _, space := range []ColorSpace{DisplayP3, AdobeRGB} {
+		tagged := TaggedImage{img, space}
+
+		// TaggedImage.At returns the values as is
+		if c := tagged.At(2, 0); c != img.At(2, 0) {
+			t.Errorf("%d: expected %v, got %v", space, img.At(2, 0), c)
+		}
+
+		lin := Linearize(tagged)
+		if lin.Space != Linear {
+			t.Fatalf("%d: expected Linear, got %d", space, lin.Space)
+		}
+		white := lin.At(0, 0).(color.NRGBA64)
+		if white.R < 0xfffe || white.G < 0xfffe || white.B < 0xfffe {
+			t.Errorf("%d: expected white, got %v", space, white)
+		}
+
+		// the gray is decoded by the transfer curve of the space
+		want := float64(SRGB16ToLinear(0x8000))
+		if space == AdobeRGB {
+			want = 65535 * math.Pow(0x8000/65535.0, 563.0/256)
+		}
+		gray := lin.At(1, 0).(color.NRGBA64)
+		if math.Abs(float64(gray.G)-want) > 2 || gray.A != 0x8000 {
+			t.Errorf("%d: expected %v, got %v", space, want, gray)
+		}
+
+		// a wide gamut green, clipped to sRGB
+		green := lin.At(2, 0).(color.NRGBA64)
+		if green.R != 0 || green.G != 0xffff {
+			t.Errorf("%d: expected a clipped green, got %v", space, green)
+		}
+
+		srgb := Delinearize(tagged)
+		if srgb.Space != SRGB {
+			t.Fatalf("%d: expected SRGB, got %d", space, srgb.Space)
+		}
+		if space == DisplayP3 {
+			if want := DisplayP3ToSRGB(tagged, Clip); !slices.Equal(srgb.Image.(*image.NRGBA64).Pix, want.Image.(*image.NRGBA64).Pix) {
+				t.Errorf("%d: Delinearize doesn't match DisplayP3ToSRGB", space)
+			}
+		}
+
+		// ToLinearFloat honors the tag
+		l := ToLinearFloat(tagged)
+		if g := l.Pix[4*1+1] / l.Pix[4*1+3]; math.Abs(float64(g)*65535-want) > 4 {
+			t.Errorf("%d: expected %v, got %v", space, want, 65535*g)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+)
+
+// IsGrayscale reports whether an image is effectively grayscale:
+// whether the R, G and B components of every pixel are within tolerance of each other (in 8-bit units).
+// Such images can be stored as an *image.Gray without loss.
+func IsGrayscale(img image.Image, tolerance uint8) bool {
+	bounds := img.Bounds()
+	tol := int(tolerance)
+
+	gray := func(r, g, b int) bool {
+		return max(r, g, b)-min(r, g, b) <= tol
+	}
+
+	switch src := img.(type) {
+	case *image.Gray, *image.Gray16:
+		return true
+
+	case *image.YCbCr:
+		if flatChroma(src) {
+			return true
+		}
+
+	case *image.Paletted:
+		// otherwise, scan: only the used entries matter
+		if grayPalette(src.Palette, gray) {
+			return true
+		}
+
+	case *image.NRGBA:
+		return grayPix(src.Pix, src.Stride, bounds, gray)
+	case *image.RGBA:
+		return grayPix(src.Pix, src.Stride, bounds, gray)
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if !gray(int(c.R), int(c.G), int(c.B)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func grayPalette(p color.Palette, gray func(r, g, b int) bool) bool {
+	for _, c := range p {
+		c := color.NRGBAModel.Convert(c).(color.NRGBA)
+		if !gray(int(c.R), int(c.G), int(c.B)) {
+			return false
+		}
+	}
+	return true
+}
+
+func grayPix(pix []uint8, stride int, bounds image.Rectangle, gray func(r, g, b int) bool) bool {
+	for y := 0; y < bounds.Dy(); y++ {
+		row := pix[y*stride : y*stride+4*bounds.Dx()]
+		for i := 0; i < len(row); i += 4 {
+			if !gray(int(row[i]), int(row[i+1]), int(row[i+2])) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// flatChroma reports whether every chroma sample of an image is neutral (128).
+func flatChroma(img *image.YCbCr) bool {
+	for _, plane := range [][]uint8{img.Cb, img.Cr} {
+		for _, c := range plane {
+			if c != 128 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// HasAlpha reports whether any pixel of an image is not fully opaque.
+// See IsOpaque.
+func HasAlpha(img image.Image) bool {
+	return !IsOpaque(img)
+}
+
+// IsOpaque reports whether every pixel of an image is fully opaque, e.g. to choose between JPEG and PNG.
+// Unlike the Opaque methods of some image types, it's exact for all images,
+// stopping at the first translucent pixel.
+// See HasAlphaChannel for a faster, conservative check.
+func IsOpaque(img image.Image) bool {
+	bounds := img.Bounds()
+
+	switch src := img.(type) {
+	case *image.Gray, *image.Gray16, *image.YCbCr, *image.CMYK:
+		return true
+	case *image.NRGBA:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 4, 3)
+	case *image.RGBA:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 4, 3)
+	case *image.NRGBA64:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 8, 6, 7)
+	case *image.RGBA64:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 8, 6, 7)
+	case *image.Alpha:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 1, 0)
+	case *image.Alpha16:
+		return opaquePix(src.Pix, src.Stride, bounds.Dx(), bounds.Dy(), 2, 0, 1)
+	case *image.NYCbCrA:
+		return opaquePix(src.A[src.AOffset(bounds.Min.X, bounds.Min.Y):], src.AStride, bounds.Dx(), bounds.Dy(), 1, 0)
+	case *image.Paletted:
+		// indices are uint8, entries beyond 256 are unused
+		var translucent [256]bool
+		for i, c := range src.Palette[:min(len(src.Palette), 256)] {
+			_, _, _, a := c.RGBA()
+			translucent[i] = a != 0xffff
+		}
+		for y := 0; y < bounds.Dy(); y++ {
+			for _, i := range src.Pix[y*src.Stride : y*src.Stride+bounds.Dx()] {
+				if int(i) >= len(src.Palette) || translucent[i] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// HasAlphaChannel reports whether an image can have translucent pixels, based only on its type:
+// it doesn't look at the pixels, except for the palette of a paletted image.
+// It's a fast, conservative heuristic: images without an alpha channel are always opaque,
+// but images with one may also be opaque. See IsOpaque.
+func HasAlphaChannel(img image.Image) bool {
+	switch src := img.(type) {
+	case *image.Gray, *image.Gray16, *image.YCbCr, *image.CMYK:
+		return false
+	case *image.Paletted:
+		for _, c := range src.Palette {
+			if _, _, _, a := c.RGBA(); a != 0xffff {
+				return true
+			}
+		}
+		return false
+	}
+	switch img.ColorModel() {
+	case color.GrayModel, color.Gray16Model, color.YCbCrModel, color.CMYKModel:
+		return false
+	}
+	return true
+}
+
+// opaquePix checks that the alpha bytes, at the given offsets within each pixel, are all 0xff.
+func opaquePix(pix []uint8, stride, width, height, bpp int, alpha ...int) bool {
+	for y := 0; y < height; y++ {
+		row := pix[y*stride : y*stride+bpp*width]
+		for i := 0; i < len(row); i += bpp {
+			for _, a := range alpha {
+				if row[i+a] != 0xff {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"testing"
+)
+
+func Test_IsGrayscale(t *testing.T) {
+	rect := image.Rect(0, 0, 16, 16)
+
+	nrgba := image.NewNRGBA(rect)
+	rgba := image.NewRGBA(rect)
+	nrgba64 := image.NewNRGBA64(rect)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			v := uint8(16*y + x)
+			nrgba.SetNRGBA(x, y, color.NRGBA{v, v, v, 255 - v})
+			rgba.SetRGBA(x, y, color.RGBA{v / 2, v / 2, v / 2, 128})
+			nrgba64.SetNRGBA64(x, y, color.NRGBA64{uint16(v) * 257, uint16(v) * 257, uint16(v) * 257, 65535})
+		}
+	}
+	ycbcr := image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+	for i := range ycbcr.Cb {
+		ycbcr.Cb[i], ycbcr.Cr[i] = 128, 128
+	}
+	paletted := image.NewPaletted(rect, palette.Plan9)
+	grays := image.NewPaletted(rect, color.Palette{color.Black, color.White})
+
+	tests := []image.Image{nrgba, rgba, nrgba64, ycbcr, paletted, grays, image.NewGray(rect)}
+	for _, img := range tests {
+		if !IsGrayscale(img, 0) {
+			t.Errorf("%T: expected grayscale", img)
+		}
+	}
+
+	// a single colored pixel
+	nrgba.SetNRGBA(7, 9, color.NRGBA{100, 102, 100, 255})
+	rgba.SetRGBA(7, 9, color.RGBA{100, 102, 100, 255})
+	nrgba64.SetNRGBA64(7, 9, color.NRGBA64{25700, 26214, 25700, 65535})
+	ycbcr.Cb[5] = 140
+	paletted.SetColorIndex(7, 9, 200)
+
+	for _, img := range []image.Image{nrgba, rgba, nrgba64, ycbcr, paletted} {
+		if IsGrayscale(img, 0) {
+			t.Errorf("%T: expected color", img)
+		}
+	}
+	for _, img := range []image.Image{nrgba, rgba, nrgba64} {
+		if !IsGrayscale(img, 2) {
+			t.Errorf("%T: expected grayscale within tolerance", img)
+		}
+	}
+}
+
+func Test_IsOpaque(t *testing.T) {
+	rect := image.Rect(0, 0, 16, 16)
+
+	nrgba := image.NewNRGBA(rect)
+	rgba := image.NewRGBA(rect)
+	nrgba64 := image.NewNRGBA64(rect)
+	rgba64 := image.NewRGBA64(rect)
+	alpha := image.NewAlpha(rect)
+	nycbcra := image.NewNYCbCrA(rect, image.YCbCrSubsampleRatio420)
+	paletted := image.NewPaletted(rect, color.Palette{color.Black, color.Transparent})
+	for _, pix := range [][]uint8{nrgba.Pix, rgba.Pix, nrgba64.Pix, rgba64.Pix, alpha.Pix, nycbcra.A} {
+		for i := range pix {
+			pix[i] = 0xff
+		}
+	}
+	wrapped := &wrapper{rgba}
+
+	tests := []image.Image{nrgba, rgba, nrgba64, rgba64, alpha, nycbcra, paletted, wrapped}
+	for _, img := range tests {
+		if !IsOpaque(img) || HasAlpha(img) {
+			t.Errorf("%T: expected opaque", img)
+		}
+		if !HasAlphaChannel(img) {
+			t.Errorf("%T: expected an alpha channel", img)
+		}
+	}
+	for _, img := range []image.Image{image.NewGray(rect), image.NewYCbCr(rect, image.YCbCrSubsampleRatio444)} {
+		if !IsOpaque(img) || HasAlphaChannel(img) {
+			t.Errorf("%T: expected opaque", img)
+		}
+	}
+
+	// a single transparent pixel
+	nrgba.SetNRGBA(7, 9, color.NRGBA{})
+	rgba.SetRGBA(7, 9, color.RGBA{})
+	nrgba64.SetNRGBA64(7, 9, color.NRGBA64{A: 0xfffe})
+	rgba64.SetRGBA64(7, 9, color.RGBA64{A: 0xff00})
+	alpha.SetAlpha(7, 9, color.Alpha{})
+	nycbcra.A[nycbcra.AOffset(7, 9)] = 0
+	paletted.SetColorIndex(7, 9, 1)
+
+	for _, img := range tests {
+		if IsOpaque(img) || !HasAlpha(img) {
+			t.Errorf("%T: expected transparent", img)
+		}
+	}
+
+	// sub-images exclude it
+	for _, img := range tests[:len(tests)-1] {
+		sub := img.(imageWithSubImage).SubImage(image.Rect(8, 0, 16, 16))
+		if !IsOpaque(sub) {
+			t.Errorf("%T: expected opaque sub-image", img)
+		}
+	}
+
+	// palettes may have more than 256 entries
+	large := make(color.Palette, 300)
+	for i := range large {
+		large[i] = color.Gray{uint8(i)}
+	}
+	large[299] = color.Transparent
+	paletted = image.NewPaletted(rect, large)
+	if !IsOpaque(paletted) || !HasAlphaChannel(paletted) {
+		t.Error("expected opaque, with an alpha channel")
+	}
+}
+
+type wrapper struct {
+	image.Image
+}
@@ -0,0 +1,136 @@
+package imageutil
+
+import (
+	"image"
+	"math"
+)
+
+// GamutMap selects how colors outside the destination gamut are brought into it.
+type GamutMap int
+
+const (
+	// Clip clamps each channel independently.
+	// This is cheap, and exact for colors in gamut, but shifts the hue of out of gamut colors.
+	Clip GamutMap = iota
+	// DesaturateToGamut pulls out of gamut colors towards the gray of the same luminance,
+	// just until they're in gamut, preserving hue and luminance.
+	// Colors in gamut are not changed.
+	DesaturateToGamut
+	// PerceptualCompress desaturates, like DesaturateToGamut, but compresses
+	// the most saturated colors smoothly into the edge of the gamut,
+	// so out of gamut gradations are preserved, at the cost of slightly desaturating
+	// some colors that were in gamut.
+	PerceptualCompress
+)
+
+// linearP3ToSRGB converts linear Display P3 to linear sRGB (both D65).
+var linearP3ToSRGB = [3][3]float64{
+	{+1.2249401762805598, -0.2249401762805598, 0},
+	{-0.0420569547096881, +1.0420569547096883, 0},
+	{-0.0196375545903344, -0.0786360455506319, +1.0982736001409662},
+}
+
+// linearAdobeToSRGB converts linear Adobe RGB (1998) to linear sRGB (both D65).
+var linearAdobeToSRGB = [3][3]float64{
+	{+1.3982832, -0.3982832, 0},
+	{0, 1, 0},
+	{0, -0.0429383, +1.0429383},
+}
+
+// DisplayP3ToSRGB converts a Display P3 image to sRGB, stored in an NRGBA64 image,
+// bringing colors outside the sRGB gamut into it, as specified by mapping.
+//
+// Untagged images are assumed to be Display P3.
+// Images tagged with other color spaces are not supported, and cause a panic.
+func DisplayP3ToSRGB(img image.Image, mapping GamutMap) TaggedImage {
+	if mapping < Clip || mapping > PerceptualCompress {
+		panic("Unknown gamut mapping")
+	}
+	if SpaceOf(img) != DisplayP3 && untag(img) != img {
+		panic("Unsupported color space")
+	}
+	return TaggedImage{wideToSRGB(img, DisplayP3, mapping, false), SRGB}
+}
+
+// wideToSRGB converts a Display P3 or Adobe RGB image to sRGB (or linear sRGB, if linear),
+// stored in an NRGBA64 image, bringing colors outside the sRGB gamut into it, as specified by mapping.
+func wideToSRGB(img image.Image, space ColorSpace, mapping GamutMap, linear bool) *image.NRGBA64 {
+	decode := func(v uint16) float64 {
+		return float64(SRGB16ToLinear(v)) / 65535
+	}
+	m := &linearP3ToSRGB
+	if space == AdobeRGB {
+		decode = func(v uint16) float64 {
+			return math.Pow(float64(v)/65535, 563.0/256)
+		}
+		m = &linearAdobeToSRGB
+	}
+
+	dst := toNRGBA64(img)
+	for i := 0; i < len(dst.Pix); i += 8 {
+		var src [3]float64
+		for c := range src {
+			src[c] = decode(uint16(dst.Pix[i+2*c])<<8 | uint16(dst.Pix[i+2*c+1]))
+		}
+
+		var rgb [3]float64
+		for c, row := range m {
+			rgb[c] = row[0]*src[0] + row[1]*src[1] + row[2]*src[2]
+		}
+		rgb = mapGamut(rgb, mapping)
+
+		for c, v := range rgb {
+			v := uint16(math.Floor(65535*math.Max(0, math.Min(v, 1)) + 0.5))
+			if !linear {
+				v = LinearToSRGB16(v)
+			}
+			dst.Pix[i+2*c], dst.Pix[i+2*c+1] = uint8(v>>8), uint8(v)
+		}
+	}
+	return dst
+}
+
+// mapGamut brings a linear sRGB color into the [0, 1] cube.
+func mapGamut(rgb [3]float64, mapping GamutMap) [3]float64 {
+	if mapping == Clip {
+		return rgb
+	}
+
+	// the gray of the same luminance
+	y := 0.2126*rgb[0] + 0.7152*rgb[1] + 0.0722*rgb[2]
+	if y <= 0 || y >= 1 {
+		return [3]float64{y, y, y}
+	}
+
+	// how far the color is from the gray, relative to the edge of the gamut (1 is on the edge)
+	var s float64
+	for _, v := range rgb {
+		if v > y {
+			s = math.Max(s, (v-y)/(1-y))
+		} else {
+			s = math.Max(s, (y-v)/y)
+		}
+	}
+
+	var t float64
+	switch mapping {
+	case DesaturateToGamut:
+		t = math.Min(s, 1)
+	case PerceptualCompress:
+		// identity up to the knee, then approaches the edge asymptotically
+		const knee = 0.8
+		t = s
+		if s > knee {
+			t = knee + (1-knee)*math.Tanh((s-knee)/(1-knee))
+		}
+	}
+	if s == 0 || t == s {
+		return rgb
+	}
+
+	f := t / s
+	for c, v := range rgb {
+		rgb[c] = y + f*(v-y)
+	}
+	return rgb
+}
@@ -0,0 +1,72 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_DisplayP3ToSRGB(t *testing.T) {
+	// hue angle of a linear RGB color, from its opponent components
+	hue := func(r, g, b float64) float64 {
+		return math.Atan2(math.Sqrt(3)*(g-b), 2*r-g-b)
+	}
+	linear := func(img image.Image) (r, g, b float64) {
+		c := img.At(0, 0).(color.NRGBA64)
+		return float64(SRGB16ToLinear(c.R)) / 65535,
+			float64(SRGB16ToLinear(c.G)) / 65535,
+			float64(SRGB16ToLinear(c.B)) / 65535
+	}
+
+	// saturated P3 colors, outside the sRGB gamut
+	for _, c := range []color.NRGBA{
+		{0, 255, 0, 255},
+		{255, 96, 0, 255},
+		{40, 80, 255, 128},
+	} {
+		img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA(0, 0, c)
+
+		// the hue of the unmapped color
+		var p3, rgb [3]float64
+		for i, v := range []uint8{c.R, c.G, c.B} {
+			p3[i] = float64(SRGB8ToLinear(v)) / 65535
+		}
+		for i, row := range linearP3ToSRGB {
+			rgb[i] = row[0]*p3[0] + row[1]*p3[1] + row[2]*p3[2]
+		}
+		want := hue(rgb[0], rgb[1], rgb[2])
+
+		shift := func(mapping GamutMap) float64 {
+			res := DisplayP3ToSRGB(TaggedImage{img, DisplayP3}, mapping)
+			if res.Space != SRGB {
+				t.Errorf("expected SRGB, got %d", res.Space)
+			}
+			if a := res.At(0, 0).(color.NRGBA64).A; a != 257*uint16(c.A) {
+				t.Errorf("expected alpha %d, got %d", 257*uint16(c.A), a)
+			}
+			d := math.Abs(hue(linear(res)) - want)
+			return math.Min(d, 2*math.Pi-d)
+		}
+
+		clip := shift(Clip)
+		desat := shift(DesaturateToGamut)
+		comp := shift(PerceptualCompress)
+		if desat >= clip || desat > 0.01 {
+			t.Errorf("%v: desaturation shifted hue by %f, clipping by %f", c, desat, clip)
+		}
+		if comp >= clip || comp > 0.01 {
+			t.Errorf("%v: compression shifted hue by %f, clipping by %f", c, comp, clip)
+		}
+	}
+
+	// colors in gamut are not desaturated
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{120, 100, 90, 255})
+	clip := DisplayP3ToSRGB(img, Clip)
+	desat := DisplayP3ToSRGB(img, DesaturateToGamut)
+	if clip.At(0, 0) != desat.At(0, 0) {
+		t.Errorf("expected %v, got %v", clip.At(0, 0), desat.At(0, 0))
+	}
+}
@@ -0,0 +1,62 @@
+package imageutil
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// ToGrayWeighted converts an image to grayscale with the given luma weights,
+// e.g. 0.299, 0.587, 0.114 for Rec. 601 (like color.GrayModel), or 0.2126, 0.7152, 0.0722 for Rec. 709.
+// Weights are normalized to sum to one.
+//
+// As the standards define luma, weights are applied to the gamma encoded (sRGB) values.
+// See ToGrayWeightedLinear to weight linear light instead.
+// The result is anchored at the origin; transparent pixels are composited over black.
+func ToGrayWeighted(img image.Image, wr, wg, wb float64) *image.Gray {
+	return toGrayWeighted(img, wr, wg, wb, false)
+}
+
+// ToGrayWeightedLinear converts an image to grayscale with the given weights,
+// applied in linear light, which computes relative luminance, then re-encoded to sRGB.
+// Weights are normalized to sum to one.
+// See ToGrayWeighted.
+func ToGrayWeightedLinear(img image.Image, wr, wg, wb float64) *image.Gray {
+	return toGrayWeighted(img, wr, wg, wb, true)
+}
+
+func toGrayWeighted(img image.Image, wr, wg, wb float64, linear bool) *image.Gray {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+	dst := image.NewGray(src.Rect)
+
+	if sum := wr + wg + wb; sum != 0 {
+		wr, wg, wb = wr/sum, wg/sum, wb/sum
+	}
+
+	var lut [3][256]float64
+	for i := range 256 {
+		v := float64(i)
+		if linear {
+			v = float64(SRGB8ToLinear(uint8(i)))
+		}
+		lut[0][i] = wr * v
+		lut[1][i] = wg * v
+		lut[2][i] = wb * v
+	}
+
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		s := src.Pix[y*src.Stride:]
+		d := dst.Pix[y*dst.Stride : y*dst.Stride+dst.Rect.Dx()]
+		for x := range d {
+			v := lut[0][s[4*x]] + lut[1][s[4*x+1]] + lut[2][s[4*x+2]]
+			if linear {
+				d[x] = LinearToSRGB8(uint16(math.Max(0, math.Min(v+0.5, 65535))))
+			} else {
+				d[x] = uint8(math.Max(0, math.Min(v+0.5, 255)))
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,44 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ToGrayWeighted(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 255, 0, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{255, 255, 255, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{128, 128, 128, 255})
+
+	rec601 := ToGrayWeighted(img, 0.299, 0.587, 0.114)
+	rec709 := ToGrayWeighted(img, 0.2126, 0.7152, 0.0722)
+	linear := ToGrayWeightedLinear(img, 0.2126, 0.7152, 0.0722)
+	scaled := ToGrayWeighted(img, 2126, 7152, 722)
+
+	if v := rec601.Pix[0]; v != 150 {
+		t.Errorf("Rec. 601 green, expected: 150, got: %d", v)
+	}
+	if v := rec709.Pix[0]; v != 182 {
+		t.Errorf("Rec. 709 green, expected: 182, got: %d", v)
+	}
+	if v := linear.Pix[0]; v != 220 {
+		t.Errorf("linear Rec. 709 green, expected: 220, got: %d", v)
+	}
+	if string(scaled.Pix) != string(rec709.Pix) {
+		t.Error("weights not normalized")
+	}
+
+	// neutral colors are preserved
+	for _, dst := range []*image.Gray{rec601, rec709, linear} {
+		if dst.Pix[1] != 255 || dst.Pix[2] != 128 {
+			t.Errorf("unexpected grays: %v", dst.Pix[1:])
+		}
+	}
+
+	// matches the standard library
+	if v := color.GrayModel.Convert(img.At(0, 0)).(color.Gray).Y; v != rec601.Pix[0] {
+		t.Errorf("expected: %d, got: %d", v, rec601.Pix[0])
+	}
+}
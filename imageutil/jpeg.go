@@ -0,0 +1,365 @@
+package imageutil
+
+import (
+	"bufio"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"math"
+)
+
+// EncodeJPEG writes an image to w in baseline JPEG format,
+// with the given quality (1 to 100) and chroma subsampling.
+//
+// The image/jpeg encoder always subsamples chroma 4:2:0,
+// which blurs sharp color edges (e.g. colored text, or line art).
+// Gray images are encoded as grayscale by image/jpeg, ignoring subsampling.
+func EncodeJPEG(w io.Writer, img image.Image, quality int, subsampleRatio image.YCbCrSubsampleRatio) error {
+	if _, ok := img.(*image.Gray); ok {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() >= 1<<16 || bounds.Dy() >= 1<<16 {
+		return errors.New("imageutil: image is too large to encode as JPEG")
+	}
+
+	sx, sy := subsampleShifts(subsampleRatio)
+	src := RGBAToYCbCr(img, subsampleRatio)
+
+	e := jpegEncoder{w: bufio.NewWriter(w)}
+	e.quant = jpegQuant(quality)
+
+	// SOI, DQT
+	e.write(0xff, 0xd8)
+	e.marker(0xdb, 2*65)
+	for i, q := range e.quant {
+		e.write(byte(i))
+		for _, v := range q {
+			e.write(byte(v))
+		}
+	}
+
+	// SOF0: luma sampling factors are relative to the subsampled chroma
+	e.marker(0xc0, 15)
+	e.write(8, byte(bounds.Dy()>>8), byte(bounds.Dy()), byte(bounds.Dx()>>8), byte(bounds.Dx()), 3)
+	e.write(1, 1<<sx<<4|1<<sy, 0)
+	e.write(2, 0x11, 1)
+	e.write(3, 0x11, 1)
+
+	// DHT
+	size := 0
+	for _, s := range jpegHuffmanSpec {
+		size += 17 + len(s.value)
+	}
+	e.marker(0xc4, size)
+	for i, s := range jpegHuffmanSpec {
+		e.write(byte(i&1<<4 | i>>1))
+		e.write(s.count[:]...)
+		e.write(s.value...)
+	}
+
+	// SOS
+	e.marker(0xda, 10)
+	e.write(3, 1, 0x00, 2, 0x11, 3, 0x11, 0, 63, 0)
+
+	w8, h8 := 8<<sx, 8<<sy
+	cw, ch := (bounds.Dx()+w8/8-1)>>sx, (bounds.Dy()+h8/8-1)>>sy
+	var block [64]float64
+	var dc [3]int
+	for my := 0; my < bounds.Dy(); my += h8 {
+		for mx := 0; mx < bounds.Dx(); mx += w8 {
+			for by := 0; by < h8; by += 8 {
+				for bx := 0; bx < w8; bx += 8 {
+					jpegBlock(&block, src.Y, src.YStride, mx+bx, my+by, bounds.Dx(), bounds.Dy())
+					dc[0] = e.block(&block, 0, dc[0])
+				}
+			}
+
+			jpegBlock(&block, src.Cb, src.CStride, mx>>sx, my>>sy, cw, ch)
+			dc[1] = e.block(&block, 1, dc[1])
+			jpegBlock(&block, src.Cr, src.CStride, mx>>sx, my>>sy, cw, ch)
+			dc[2] = e.block(&block, 1, dc[2])
+		}
+	}
+
+	// pad the last byte with ones, EOI
+	e.bits(0x7f, 7)
+	e.write(0xff, 0xd9)
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+type jpegEncoder struct {
+	w     *bufio.Writer
+	err   error
+	acc   uint32
+	nbits uint
+	quant [2][64]uint8
+}
+
+func (e *jpegEncoder) write(b ...byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *jpegEncoder) marker(m byte, size int) {
+	e.write(0xff, m, byte((size+2)>>8), byte(size+2))
+}
+
+// bits writes the n least significant bits of b, stuffing a zero after each 0xff byte.
+func (e *jpegEncoder) bits(b uint32, n uint) {
+	e.acc = e.acc<<n | b&(1<<n-1)
+	e.nbits += n
+	for e.nbits >= 8 {
+		e.nbits -= 8
+		v := byte(e.acc >> e.nbits)
+		if v == 0xff {
+			e.write(v, 0)
+		} else {
+			e.write(v)
+		}
+	}
+}
+
+// huffman writes a value with the Huffman table at index t.
+func (e *jpegEncoder) huffman(t int, v byte) {
+	c := jpegHuffmanCodes[t][v]
+	e.bits(c>>8, uint(c&0xff))
+}
+
+// emit writes a Huffman coded (run, size) symbol, followed by the size bits of v.
+func (e *jpegEncoder) emit(t int, run int, v int) {
+	a, n := v, uint(0)
+	if v < 0 {
+		a, v = -v, v-1
+	}
+	for a != 0 {
+		a >>= 1
+		n++
+	}
+	e.huffman(t, byte(run<<4|int(n)))
+	if n > 0 {
+		e.bits(uint32(v), n)
+	}
+}
+
+// block transforms, quantizes and writes a block with table q,
+// returning the DC value to predict the next block of the component.
+func (e *jpegEncoder) block(b *[64]float64, q int, prev int) int {
+	fdct(b)
+
+	// DC coefficients take 11 bits, AC coefficients 10 bits
+	var coef [64]int
+	for i, z := range jpegZigzag {
+		coef[i] = max(-1023, min(int(math.Round(b[z]/float64(e.quant[q][i]))), 1023))
+	}
+	coef[0] = max(-1024, min(int(math.Round(b[0]/float64(e.quant[q][0]))), 1023))
+
+	e.emit(2*q, 0, coef[0]-prev)
+
+	run := 0
+	for _, v := range coef[1:] {
+		if v == 0 {
+			run++
+			continue
+		}
+		for run > 15 {
+			e.huffman(2*q+1, 0xf0)
+			run -= 16
+		}
+		e.emit(2*q+1, run, v)
+		run = 0
+	}
+	if run > 0 {
+		e.huffman(2*q+1, 0x00)
+	}
+	return coef[0]
+}
+
+// jpegBlock extracts an 8×8 block of a plane, level shifted,
+// replicating the edges of a w×h plane.
+func jpegBlock(b *[64]float64, pix []uint8, stride, x0, y0, w, h int) {
+	for y := 0; y < 8; y++ {
+		row := min(y0+y, h-1) * stride
+		for x := 0; x < 8; x++ {
+			b[8*y+x] = float64(pix[row+min(x0+x, w-1)]) - 128
+		}
+	}
+}
+
+// fdct computes the 2D forward DCT of an 8×8 block, in place.
+func fdct(b *[64]float64) {
+	var tmp [64]float64
+	for u := 0; u < 8; u++ {
+		for y := 0; y < 8; y++ {
+			var s float64
+			for x := 0; x < 8; x++ {
+				s += b[8*y+x] * dctCos[x][u]
+			}
+			tmp[8*y+u] = s
+		}
+	}
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var s float64
+			for y := 0; y < 8; y++ {
+				s += tmp[8*y+u] * dctCos[y][v]
+			}
+			b[8*v+u] = s / 4
+		}
+	}
+}
+
+// dctCos[x][u] is C(u)·cos((2x+1)uπ/16).
+var dctCos = func() (c [8][8]float64) {
+	for x := range c {
+		for u := range c[x] {
+			c[x][u] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / 16)
+			if u == 0 {
+				c[x][u] = math.Sqrt2 / 2
+			}
+		}
+	}
+	return c
+}()
+
+// jpegQuant scales the quantization tables of the JPEG spec (section K.1) for a quality.
+func jpegQuant(quality int) (q [2][64]uint8) {
+	quality = max(1, min(quality, 100))
+	scale := 200 - 2*quality
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	for i := range q {
+		for j, v := range jpegUnscaledQuant[i] {
+			q[i][j] = uint8(max(1, min((int(v)*scale+50)/100, 255)))
+		}
+	}
+	return q
+}
+
+// jpegZigzag maps zig-zag order to natural order.
+var jpegZigzag = [64]uint8{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// jpegUnscaledQuant are the luma and chroma quantization tables of the JPEG spec, in zig-zag order.
+var jpegUnscaledQuant = [2][64]uint8{
+	{
+		16, 11, 12, 14, 12, 10, 16, 14,
+		13, 14, 18, 17, 16, 19, 24, 40,
+		26, 24, 22, 22, 24, 49, 35, 37,
+		29, 40, 58, 51, 61, 60, 57, 51,
+		56, 55, 64, 72, 92, 78, 64, 68,
+		87, 69, 55, 56, 80, 109, 81, 87,
+		95, 98, 103, 104, 103, 62, 77, 113,
+		121, 112, 100, 120, 92, 101, 103, 99,
+	},
+	{
+		17, 18, 18, 24, 21, 24, 47, 26,
+		26, 47, 99, 66, 56, 66, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	},
+}
+
+// jpegHuffmanSpec are the luma DC and AC, and chroma DC and AC,
+// Huffman tables of the JPEG spec (section K.3).
+var jpegHuffmanSpec = [4]struct {
+	count [16]byte
+	value []byte
+}{
+	{
+		[16]byte{0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 3, 3, 2, 4, 3, 5, 5, 4, 4, 0, 0, 1, 125},
+		[]byte{
+			0x01, 0x02, 0x03, 0x00, 0x04, 0x11, 0x05, 0x12,
+			0x21, 0x31, 0x41, 0x06, 0x13, 0x51, 0x61, 0x07,
+			0x22, 0x71, 0x14, 0x32, 0x81, 0x91, 0xa1, 0x08,
+			0x23, 0x42, 0xb1, 0xc1, 0x15, 0x52, 0xd1, 0xf0,
+			0x24, 0x33, 0x62, 0x72, 0x82, 0x09, 0x0a, 0x16,
+			0x17, 0x18, 0x19, 0x1a, 0x25, 0x26, 0x27, 0x28,
+			0x29, 0x2a, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+			0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+			0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+			0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+			0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79,
+			0x7a, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89,
+			0x8a, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98,
+			0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+			0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6,
+			0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3, 0xc4, 0xc5,
+			0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2, 0xd3, 0xd4,
+			0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda, 0xe1, 0xe2,
+			0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9, 0xea,
+			0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+	{
+		[16]byte{0, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 2, 4, 4, 3, 4, 7, 5, 4, 4, 0, 1, 2, 119},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x11, 0x04, 0x05, 0x21,
+			0x31, 0x06, 0x12, 0x41, 0x51, 0x07, 0x61, 0x71,
+			0x13, 0x22, 0x32, 0x81, 0x08, 0x14, 0x42, 0x91,
+			0xa1, 0xb1, 0xc1, 0x09, 0x23, 0x33, 0x52, 0xf0,
+			0x15, 0x62, 0x72, 0xd1, 0x0a, 0x16, 0x24, 0x34,
+			0xe1, 0x25, 0xf1, 0x17, 0x18, 0x19, 0x1a, 0x26,
+			0x27, 0x28, 0x29, 0x2a, 0x35, 0x36, 0x37, 0x38,
+			0x39, 0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48,
+			0x49, 0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58,
+			0x59, 0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68,
+			0x69, 0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78,
+			0x79, 0x7a, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
+			0x88, 0x89, 0x8a, 0x92, 0x93, 0x94, 0x95, 0x96,
+			0x97, 0x98, 0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5,
+			0xa6, 0xa7, 0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4,
+			0xb5, 0xb6, 0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3,
+			0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2,
+			0xd3, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda,
+			0xe2, 0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9,
+			0xea, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+}
+
+// jpegHuffmanCodes maps the values of each Huffman table to their code<<8 | length.
+var jpegHuffmanCodes = func() (codes [4][256]uint32) {
+	for i, s := range jpegHuffmanSpec {
+		code, k := uint32(0), 0
+		for n, count := range s.count {
+			for range count {
+				codes[i][s.value[k]] = code<<8 | uint32(n+1)
+				code++
+				k++
+			}
+			code <<= 1
+		}
+	}
+	return codes
+}()
@@ -0,0 +1,83 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func Test_EncodeJPEG(t *testing.T) {
+	// sharp color edges: red and blue stripes, over a gray gradient
+	img := image.NewRGBA(image.Rect(3, 5, 67, 53))
+	for y := 5; y < 53; y++ {
+		for x := 3; x < 67; x++ {
+			c := color.RGBA{uint8(4 * x), 128, uint8(4 * y), 255}
+			switch x % 4 {
+			case 0:
+				c = color.RGBA{255, 0, 0, 255}
+			case 2:
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	mse := func(dec image.Image) (err float64) {
+		for y := 0; y < 48; y++ {
+			for x := 0; x < 64; x++ {
+				r0, g0, b0, _ := img.At(x+3, y+5).RGBA()
+				r1, g1, b1, _ := dec.At(x, y).RGBA()
+				for _, d := range []float64{
+					float64(r0>>8) - float64(r1>>8),
+					float64(g0>>8) - float64(g1>>8),
+					float64(b0>>8) - float64(b1>>8),
+				} {
+					err += d * d
+				}
+			}
+		}
+		return err / (3 * 48 * 64)
+	}
+
+	encode := func(img image.Image, ratio image.YCbCrSubsampleRatio) ([]byte, image.Image) {
+		var buf bytes.Buffer
+		if err := EncodeJPEG(&buf, img, 90, ratio); err != nil {
+			t.Fatal(err)
+		}
+		dec, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%v: %v", ratio, err)
+		}
+		if dec.(*image.YCbCr).SubsampleRatio != ratio {
+			t.Errorf("expected %v, got %v", ratio, dec.(*image.YCbCr).SubsampleRatio)
+		}
+		return buf.Bytes(), dec
+	}
+
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	} {
+		// odd sizes exercise partial blocks
+		sub := img.SubImage(image.Rect(4, 6, 41, 35))
+		_, dec := encode(sub, ratio)
+		if r := image.Rect(0, 0, 37, 29); dec.Bounds() != r {
+			t.Errorf("%v: expected: %v, got: %v", ratio, r, dec.Bounds())
+		}
+	}
+
+	data444, dec444 := encode(img, image.YCbCrSubsampleRatio444)
+	data420, dec420 := encode(img, image.YCbCrSubsampleRatio420)
+	if len(data444) <= len(data420) {
+		t.Errorf("expected 4:4:4 to be larger: %d, %d bytes", len(data444), len(data420))
+	}
+	if mse444, mse420 := mse(dec444), mse(dec420); mse444 >= mse420 || mse444 > 30 {
+		t.Errorf("expected 4:4:4 to have higher fidelity: %f, %f", mse444, mse420)
+	}
+}
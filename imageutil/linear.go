@@ -0,0 +1,144 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// LinearRGBA is an in-memory image of premultiplied, linear light, RGBA float32 values in [0, 1].
+//
+// It is the working format for filters that blur, blend, or average pixels,
+// which must be done in linear light to be physically correct.
+type LinearRGBA struct {
+	// Pix holds the image's pixels, in R, G, B, A order. The pixel at
+	// (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*4].
+	Pix []float32
+	// Stride is the Pix stride (in elements) between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+}
+
+// NewLinearRGBA returns a new, transparent, LinearRGBA image with the given bounds,
+// with a tightly packed stride (Stride == 4*width).
+func NewLinearRGBA(r image.Rectangle) *LinearRGBA {
+	return &LinearRGBA{make([]float32, 4*r.Dx()*r.Dy()), 4 * r.Dx(), r}
+}
+
+// ColorModel returns color.RGBA64Model: pixels are converted to sRGB by At.
+func (l *LinearRGBA) ColorModel() color.Model { return color.RGBA64Model }
+
+// Bounds returns the domain for which At can return non-zero color.
+func (l *LinearRGBA) Bounds() image.Rectangle { return l.Rect }
+
+// At returns the color of the pixel at (x, y), converted to sRGB.
+func (l *LinearRGBA) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(l.Rect)) {
+		return color.RGBA64{}
+	}
+	p := l.Pix[l.PixOffset(x, y):]
+	a := p[3]
+	if a <= 0 {
+		return color.RGBA64{}
+	}
+	c := color.NRGBA64{
+		LinearToSRGB16(unit16(p[0] / a)),
+		LinearToSRGB16(unit16(p[1] / a)),
+		LinearToSRGB16(unit16(p[2] / a)),
+		unit16(a),
+	}
+	return color.RGBA64Model.Convert(c)
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to the pixel at (x, y).
+func (l *LinearRGBA) PixOffset(x, y int) int {
+	return (y-l.Rect.Min.Y)*l.Stride + (x-l.Rect.Min.X)*4
+}
+
+// ToLinearFloat converts an image to linear light, with premultiplied alpha.
+// The result is anchored at the origin, with a tightly packed stride (Stride == 4*width).
+// Images tagged as Linear (see SpaceOf) are copied without conversion;
+// images tagged with other color spaces are converted by Linearize.
+func ToLinearFloat(img image.Image) *LinearRGBA {
+	bounds := img.Bounds()
+	switch SpaceOf(img) {
+	case Linear:
+		return linearFloat(img)
+	case DisplayP3, AdobeRGB:
+		return linearFloat(Linearize(img))
+	}
+	src, ok := img.(*image.NRGBA)
+	if !ok {
+		src = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+	}
+	dst := NewLinearRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := 0; y < bounds.Dy(); y++ {
+		s := src.Pix[y*src.Stride:]
+		d := dst.Pix[y*dst.Stride:]
+		for x := 0; x < bounds.Dx(); x++ {
+			a := float32(s[3]) / 255
+			d[0] = a * float32(SRGB8ToLinear(s[0])) / 65535
+			d[1] = a * float32(SRGB8ToLinear(s[1])) / 65535
+			d[2] = a * float32(SRGB8ToLinear(s[2])) / 65535
+			d[3] = a
+			s = s[4:]
+			d = d[4:]
+		}
+	}
+	return dst
+}
+
+// linearFloat copies an image that is already in linear light.
+func linearFloat(img image.Image) *LinearRGBA {
+	bounds := img.Bounds()
+	dst := NewLinearRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	if src, ok := untag(img).(*LinearRGBA); ok {
+		for y := 0; y < bounds.Dy(); y++ {
+			copy(dst.Pix[y*dst.Stride:(y+1)*dst.Stride], src.Pix[y*src.Stride:])
+		}
+		return dst
+	}
+
+	src := toNRGBA64(img)
+	for i := 0; i < len(dst.Pix); i += 4 {
+		s := src.Pix[2*i : 2*i+8 : 2*i+8]
+		a := float32(uint16(s[6])<<8|uint16(s[7])) / 65535
+		dst.Pix[i+0] = a * float32(uint16(s[0])<<8|uint16(s[1])) / 65535
+		dst.Pix[i+1] = a * float32(uint16(s[2])<<8|uint16(s[3])) / 65535
+		dst.Pix[i+2] = a * float32(uint16(s[4])<<8|uint16(s[5])) / 65535
+		dst.Pix[i+3] = a
+	}
+	return dst
+}
+
+// FromLinearFloat converts an image in linear light back to sRGB, with straight alpha.
+// Values are rounded, and clamped to [0, 1]. The result has the bounds of l.
+func FromLinearFloat(l *LinearRGBA) *image.NRGBA {
+	dst := image.NewNRGBA(l.Rect)
+
+	for y := 0; y < l.Rect.Dy(); y++ {
+		s := l.Pix[y*l.Stride:]
+		d := dst.Pix[y*dst.Stride:]
+		for x := 0; x < l.Rect.Dx(); x++ {
+			if a := s[3]; a > 0 {
+				d[0] = LinearToSRGB8(unit16(s[0] / a))
+				d[1] = LinearToSRGB8(unit16(s[1] / a))
+				d[2] = LinearToSRGB8(unit16(s[2] / a))
+				d[3] = uint8(math.Floor(255*math.Min(float64(a), 1) + 0.5))
+			}
+			s = s[4:]
+			d = d[4:]
+		}
+	}
+	return dst
+}
+
+// unit16 converts a value in [0, 1] to a rounded, clamped, 16-bit value.
+func unit16(v float32) uint16 {
+	return uint16(math.Floor(65535*math.Max(0, math.Min(float64(v), 1)) + 0.5))
+}
@@ -0,0 +1,56 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_LinearFloat(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(3, 3, 259, 19))
+	for y := 3; y < 19; y++ {
+		for x := 3; x < 259; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x - 3), uint8(255 - x), uint8(16 * y), 255})
+		}
+	}
+
+	lin := ToLinearFloat(img)
+	if r := image.Rect(0, 0, 256, 16); lin.Rect != r || lin.Stride != 4*256 {
+		t.Fatalf("unexpected bounds: %v, %d", lin.Rect, lin.Stride)
+	}
+
+	// opaque round trip is lossless
+	dst := FromLinearFloat(lin)
+	if string(dst.Pix) != string(img.Pix) {
+		t.Error("opaque round trip is not lossless")
+	}
+
+	// a gradient with partial alpha
+	for y := 3; y < 19; y++ {
+		for x := 3; x < 259; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x - 3), 200, uint8(16 * y), uint8(16*y + 7)})
+		}
+	}
+	dst = FromLinearFloat(ToLinearFloat(img.SubImage(image.Rect(5, 4, 200, 18))))
+	for y := 4; y < 18; y++ {
+		for x := 5; x < 200; x++ {
+			exp := img.NRGBAAt(x, y)
+			res := dst.NRGBAAt(x-5, y-4)
+			if exp != res {
+				t.Fatalf("at %dx%d, expected: %v, got: %v", x, y, exp, res)
+			}
+		}
+	}
+
+	// At converts to sRGB
+	lin = ToLinearFloat(img)
+	for y := 0; y < 16; y += 5 {
+		for x := 0; x < 256; x += 15 {
+			exp := color.RGBAModel.Convert(img.At(x+3, y+3))
+			res := color.RGBAModel.Convert(lin.At(x, y))
+			if exp != res {
+				t.Errorf("at %dx%d, expected: %v, got: %v", x, y, exp, res)
+			}
+		}
+	}
+}
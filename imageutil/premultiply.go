@@ -0,0 +1,76 @@
+package imageutil
+
+import (
+	"image"
+)
+
+// ToRGBAPremultiplied converts an NRGBA image to an RGBA image.
+// The result matches converting each pixel with color.RGBAModel exactly.
+func ToRGBAPremultiplied(img *image.NRGBA) *image.RGBA {
+	dst := image.NewRGBA(img.Rect)
+
+	var dst_row, src_row int
+	src_row = img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		dst_pix := dst_row
+		src_pix := src_row
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			s := img.Pix[src_pix : src_pix+4 : src_pix+4]
+			d := dst.Pix[dst_pix : dst_pix+4 : dst_pix+4]
+			a := uint32(s[3])
+			d[0] = premultiply8(uint32(s[0]), a)
+			d[1] = premultiply8(uint32(s[1]), a)
+			d[2] = premultiply8(uint32(s[2]), a)
+			d[3] = uint8(a)
+			dst_pix += 4
+			src_pix += 4
+		}
+		dst_row += dst.Stride
+		src_row += img.Stride
+	}
+
+	return dst
+}
+
+// same as color.NRGBA.RGBA, truncated to 8-bit
+func premultiply8(c, a uint32) uint8 {
+	return uint8(c * 0x101 * a / 0xff >> 8)
+}
+
+// NRGBA64ToRGBA64Exact converts an NRGBA64 image to an RGBA64 image,
+// premultiplying each color by alpha with exact rounding: round(color * alpha / 65535).
+// Converting with color.RGBA64Model truncates instead.
+func NRGBA64ToRGBA64Exact(img *image.NRGBA64) *image.RGBA64 {
+	dst := image.NewRGBA64(img.Rect)
+
+	var dst_row, src_row int
+	src_row = img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		dst_pix := dst_row
+		src_pix := src_row
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			s := img.Pix[src_pix : src_pix+8 : src_pix+8]
+			d := dst.Pix[dst_pix : dst_pix+8 : dst_pix+8]
+			a := uint32(s[6])<<8 | uint32(s[7])
+			for i := 0; i < 6; i += 2 {
+				c := premultiply16(uint32(s[i])<<8|uint32(s[i+1]), a)
+				d[i+0] = uint8(c >> 8)
+				d[i+1] = uint8(c)
+			}
+			d[6] = s[6]
+			d[7] = s[7]
+			dst_pix += 8
+			src_pix += 8
+		}
+		dst_row += dst.Stride
+		src_row += img.Stride
+	}
+
+	return dst
+}
+
+// round(c * a / 65535), exact; no ties are possible, as 65535 is odd
+func premultiply16(c, a uint32) uint32 {
+	// divsqr257rnd divides by 257², and its range is too small for 16-bit products
+	return uint32((uint64(c)*uint64(a) + 0x7fff) / 0xffff)
+}
@@ -0,0 +1,94 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_ToRGBAPremultiplied(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	for a := 0; a < 256; a++ {
+		for c := 0; c < 256; c++ {
+			img.SetNRGBA(c, a, color.NRGBA{uint8(c), uint8(255 - c), uint8(c ^ a), uint8(a)})
+		}
+	}
+
+	testSub := func(img *image.NRGBA) {
+		dst := ToRGBAPremultiplied(img)
+
+		bounds := img.Bounds()
+		if bounds != dst.Bounds() {
+			t.Errorf("bounds don't match")
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				src := img.NRGBAAt(x, y)
+				exp := color.RGBAModel.Convert(src)
+				res := dst.RGBAAt(x, y)
+				if exp != res {
+					t.Fatalf("at %v, expected: %v, got: %v", src, exp, res)
+				}
+				r0, g0, b0, a0 := src.RGBA()
+				r1, g1, b1, a1 := res.RGBA()
+				if r0>>8 != r1>>8 || g0>>8 != g1>>8 || b0>>8 != b1>>8 || a0 != a1 {
+					t.Fatalf("at %v, expected: %v, got: %v", src, exp, res)
+				}
+			}
+		}
+	}
+
+	testSub(img)
+	testSub(img.SubImage(image.Rect(3, 5, 250, 200)).(*image.NRGBA))
+}
+
+func Test_NRGBA64ToRGBA64Exact(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			a := uint16(y*257 + x)
+			c := uint16(x*257 + y*97)
+			img.SetNRGBA64(x, y, color.NRGBA64{c, 65535 - c, c ^ a, a})
+		}
+	}
+	img.SetNRGBA64(0, 0, color.NRGBA64{65535, 0, 1, 65535})
+	img.SetNRGBA64(1, 0, color.NRGBA64{65535, 65534, 32768, 32767})
+
+	round := func(c, a uint16) uint16 {
+		return uint16(math.Round(float64(c) * float64(a) / 65535))
+	}
+
+	testSub := func(img *image.NRGBA64) {
+		dst := NRGBA64ToRGBA64Exact(img)
+
+		bounds := img.Bounds()
+		if bounds != dst.Bounds() {
+			t.Errorf("bounds don't match")
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				src := img.NRGBA64At(x, y)
+				exp := color.RGBA64{round(src.R, src.A), round(src.G, src.A), round(src.B, src.A), src.A}
+				res := dst.RGBA64At(x, y)
+				if exp != res {
+					t.Fatalf("at %v, expected: %v, got: %v", src, exp, res)
+				}
+			}
+		}
+	}
+
+	testSub(img)
+	testSub(img.SubImage(image.Rect(3, 5, 250, 200)).(*image.NRGBA64))
+}
+
+func Test_premultiply16(t *testing.T) {
+	for a := uint32(0); a < 65536; a += 251 {
+		for c := uint32(0); c < 65536; c++ {
+			exp := uint32(math.Round(float64(c) * float64(a) / 65535))
+			if res := premultiply16(c, a); exp != res {
+				t.Fatalf("at %d*%d, expected: %d, got: %d", c, a, exp, res)
+			}
+		}
+	}
+}
@@ -3,10 +3,12 @@ package imageutil
 
 import (
 	"image"
+	"image/color"
 )
 
 // YCbCrUpsample upsamples a chroma subsampled YCbCr image.
 // The returned image has YCbCrSubsampleRatio444.
+// If img already has YCbCrSubsampleRatio444, it is returned as is.
 func YCbCrUpsample(img *image.YCbCr) *image.YCbCr {
 	if img.SubsampleRatio == image.YCbCrSubsampleRatio444 {
 		return img
@@ -20,6 +22,7 @@ func YCbCrUpsample(img *image.YCbCr) *image.YCbCr {
 
 // NYCbCrAUpsample upsamples a chroma subsampled NYCbCrA image.
 // The returned image has YCbCrSubsampleRatio444.
+// If img already has YCbCrSubsampleRatio444, it is returned as is.
 func NYCbCrAUpsample(img *image.NYCbCrA) *image.NYCbCrA {
 	if img.SubsampleRatio == image.YCbCrSubsampleRatio444 {
 		return img
@@ -32,6 +35,62 @@ func NYCbCrAUpsample(img *image.NYCbCrA) *image.NYCbCrA {
 	return dst
 }
 
+// YCbCrResample converts a YCbCr image to another chroma subsampling.
+// Chroma is upsampled by replication, and downsampled by averaging each block of subsampled pixels.
+// If img already has that subsampling, it is returned as is; otherwise, the result is a copy with the bounds of img.
+func YCbCrResample(img *image.YCbCr, subsampleRatio image.YCbCrSubsampleRatio) *image.YCbCr {
+	if img.SubsampleRatio == subsampleRatio {
+		return img
+	}
+
+	full := YCbCrUpsample(img)
+	if subsampleRatio == image.YCbCrSubsampleRatio444 {
+		return full
+	}
+
+	dst := image.NewYCbCr(img.Rect, subsampleRatio)
+	resample(dst.Y, dst.YStride, img.Y, img.YStride, img.Rect.Dy())
+	downsample(full, dst)
+	return dst
+}
+
+// RGBAToYCbCr converts an image to a YCbCr image with the given chroma subsampling.
+// Chroma is downsampled by averaging each block of subsampled pixels.
+// Transparent pixels are composited on black, as by image/jpeg.
+// The result is anchored at the origin.
+// If img is already a YCbCr image anchored at the origin, with that subsampling, it is returned as is.
+func RGBAToYCbCr(img image.Image, subsampleRatio image.YCbCrSubsampleRatio) *image.YCbCr {
+	if src, ok := img.(*image.YCbCr); ok && src.SubsampleRatio == subsampleRatio && src.Rect.Min == (image.Point{}) {
+		return src
+	}
+
+	src := Canonical(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewYCbCr(src.Rect, subsampleRatio)
+
+	// full resolution chroma planes
+	full := image.NewYCbCr(src.Rect, image.YCbCrSubsampleRatio444)
+	if subsampleRatio == image.YCbCrSubsampleRatio444 {
+		full = dst
+	}
+
+	for y := 0; y < h; y++ {
+		src_row := src.Pix[y*src.Stride : y*src.Stride+4*w]
+		for x := 0; x < w; x++ {
+			s := src_row[4*x : 4*x+3 : 4*x+3]
+			yy, cb, cr := color.RGBToYCbCr(s[0], s[1], s[2])
+			dst.Y[y*dst.YStride+x] = yy
+			full.Cb[y*full.CStride+x] = cb
+			full.Cr[y*full.CStride+x] = cr
+		}
+	}
+
+	if full != dst {
+		downsample(full, dst)
+	}
+	return dst
+}
+
 func resample(dst []uint8, dst_stride int, src []uint8, src_stride int, count int) {
 	var dst_row, src_row int
 	for i := 0; i < count; i++ {
@@ -67,6 +126,31 @@ func upsample(src, dst *image.YCbCr) {
 	}
 }
 
+func downsample(src, dst *image.YCbCr) {
+	sx, sy := subsampleShifts(dst.SubsampleRatio)
+	r := src.Rect
+
+	for cy := r.Min.Y >> sy; cy<<sy < r.Max.Y; cy++ {
+		y0, y1 := max(cy<<sy, r.Min.Y), min((cy+1)<<sy, r.Max.Y)
+		dst_row := (cy - r.Min.Y>>sy) * dst.CStride
+		for cx := r.Min.X >> sx; cx<<sx < r.Max.X; cx++ {
+			x0, x1 := max(cx<<sx, r.Min.X), min((cx+1)<<sx, r.Max.X)
+
+			var cb, cr, n int
+			for y := y0; y < y1; y++ {
+				src_row := (y - r.Min.Y) * src.CStride
+				for x := x0; x < x1; x++ {
+					cb += int(src.Cb[src_row+x-r.Min.X])
+					cr += int(src.Cr[src_row+x-r.Min.X])
+					n++
+				}
+			}
+			dst.Cb[dst_row+cx-r.Min.X>>sx] = uint8((cb + n/2) / n)
+			dst.Cr[dst_row+cx-r.Min.X>>sx] = uint8((cr + n/2) / n)
+		}
+	}
+}
+
 func subsampleShifts(subsampleRatio image.YCbCrSubsampleRatio) (sx, sy uint8) {
 	switch subsampleRatio {
 	case image.YCbCrSubsampleRatio444:
@@ -1,12 +1,18 @@
 package imageutil
 
 import (
+	"flag"
 	"image"
+	"image/color"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 func Test_YCbCrUpsample(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
 	var subsample string
 	rect := image.Rect(0, 0, 16, 16)
 
@@ -53,25 +59,51 @@ func Test_YCbCrUpsample(t *testing.T) {
 		subsample = "(" + sr.String() + ")"
 		{
 			img := image.NewYCbCr(rect, sr)
-			random(img.Y)
-			random(img.Cb)
-			random(img.Cr)
+			fillRandom(img.Y, next())
+			fillRandom(img.Cb, next())
+			fillRandom(img.Cr, next())
 			testImg(img)
 		}
 		{
 			img := image.NewNYCbCrA(rect, sr)
-			random(img.Y)
-			random(img.Cb)
-			random(img.Cr)
-			random(img.A)
+			fillRandom(img.Y, next())
+			fillRandom(img.Cb, next())
+			fillRandom(img.Cr, next())
+			fillRandom(img.A, next())
 			testImg(img)
 		}
 	}
 }
 
-func random(pix []uint8) {
-	for i := range pix {
-		pix[i] = uint8(rand.Int63())
+var seedFlag = flag.Int64("seed", 0, "seed for random test images (0 for a time based seed)")
+
+// testSeed returns the seed for a test's random images, and logs it,
+// so failures can be reproduced with -seed.
+func testSeed(t *testing.T) int64 {
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("seed: %d", seed)
+	return seed
+}
+
+// fillRandom fills pix deterministically from seed.
+func fillRandom(pix []uint8, seed int64) {
+	rand.New(rand.NewSource(seed)).Read(pix)
+}
+
+func Test_fillRandom(t *testing.T) {
+	a := make([]uint8, 64)
+	b := make([]uint8, 64)
+	fillRandom(a, 42)
+	fillRandom(b, 42)
+	if string(a) != string(b) {
+		t.Error("same seed, different pixels")
+	}
+	fillRandom(b, 43)
+	if string(a) == string(b) {
+		t.Error("different seeds, same pixels")
 	}
 }
 
@@ -79,3 +111,118 @@ type imageWithSubImage interface {
 	image.Image
 	SubImage(image.Rectangle) image.Image
 }
+
+func Test_RGBAToYCbCr(t *testing.T) {
+	img := image.NewRGBA(image.Rect(1, 1, 4, 3))
+	fillRandom(img.Pix, testSeed(t))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	full := RGBAToYCbCr(img, image.YCbCrSubsampleRatio444)
+	half := RGBAToYCbCr(img, image.YCbCrSubsampleRatio420)
+	if r := image.Rect(0, 0, 3, 2); full.Rect != r || half.Rect != r {
+		t.Fatalf("expected: %v, got: %v, %v", r, full.Rect, half.Rect)
+	}
+
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			c := img.RGBAAt(x+1, y+1)
+			yy, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			if got := full.YCbCrAt(x, y); got != (color.YCbCr{yy, cb, cr}) {
+				t.Errorf("at %dx%d: expected %v, got %v", x, y, color.YCbCr{yy, cb, cr}, got)
+			}
+			if half.Y[half.YOffset(x, y)] != yy {
+				t.Errorf("at %dx%d: luma doesn't match", x, y)
+			}
+		}
+	}
+
+	// chroma is the average of each 2×2 block, partial at the right edge
+	avg := func(x0, x1 int) (cb, cr int) {
+		for y := 0; y < 2; y++ {
+			for x := x0; x < x1; x++ {
+				cb += int(full.Cb[full.COffset(x, y)])
+				cr += int(full.Cr[full.COffset(x, y)])
+			}
+		}
+		n := 2 * (x1 - x0)
+		return (cb + n/2) / n, (cr + n/2) / n
+	}
+	for i, x := range []int{0, 2} {
+		cb, cr := avg(x, min(x+2, 3))
+		if int(half.Cb[i]) != cb || int(half.Cr[i]) != cr {
+			t.Errorf("chroma %d: expected %d,%d, got %d,%d", i, cb, cr, half.Cb[i], half.Cr[i])
+		}
+	}
+}
+
+func Test_YCbCrResample(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	ratios := []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	}
+
+	for _, from := range ratios {
+		img := image.NewYCbCr(image.Rect(0, 0, 16, 16), from)
+		fillRandom(img.Y, next())
+		fillRandom(img.Cb, next())
+		fillRandom(img.Cr, next())
+
+		for _, r := range []image.Rectangle{img.Rect, image.Rect(1, 3, 14, 15)} {
+			src := img.SubImage(r).(*image.YCbCr)
+			full := YCbCrUpsample(src)
+
+			for _, to := range ratios {
+				dst := YCbCrResample(full, to)
+				if dst.SubsampleRatio != to || dst.Rect != r {
+					t.Fatalf("%v→%v: unexpected result: %v, %v", from, to, dst.SubsampleRatio, dst.Rect)
+				}
+
+				// downsampling replicated chroma, back to the original ratio, is lossless
+				if to == from {
+					back := YCbCrResample(full, to)
+					for y := r.Min.Y; y < r.Max.Y; y++ {
+						for x := r.Min.X; x < r.Max.X; x++ {
+							if src.At(x, y) != back.At(x, y) {
+								t.Fatalf("%v→%v: colors don't match at %2dx%d", from, to, x, y)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_identity(t *testing.T) {
+	ycc := image.NewYCbCr(image.Rect(0, 0, 64, 64), image.YCbCrSubsampleRatio420)
+	full := image.NewYCbCr(image.Rect(0, 0, 64, 64), image.YCbCrSubsampleRatio444)
+	nycca := image.NewNYCbCrA(image.Rect(0, 0, 64, 64), image.YCbCrSubsampleRatio444)
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	linear := Linearize(nrgba)
+
+	tests := map[string]func() bool{
+		"YCbCrResample":   func() bool { return YCbCrResample(ycc, image.YCbCrSubsampleRatio420) == ycc },
+		"YCbCrUpsample":   func() bool { return YCbCrUpsample(full) == full },
+		"NYCbCrAUpsample": func() bool { return NYCbCrAUpsample(nycca) == nycca },
+		"RGBAToYCbCr":     func() bool { return RGBAToYCbCr(ycc, image.YCbCrSubsampleRatio420) == ycc },
+		"Linearize":       func() bool { return Linearize(&linear).Image == linear.Image },
+		"Delinearize":     func() bool { return Delinearize(nrgba).Image == image.Image(nrgba) },
+	}
+	for name, f := range tests {
+		if !f() {
+			t.Errorf("%s: expected the source image", name)
+		}
+		if n := testing.AllocsPerRun(10, func() { f() }); n != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, n)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package imageutil
+
+import (
+	"errors"
+	"image"
+	"image/color"
+)
+
+// YUVPlanarToRGBA converts planar Y'CbCr (e.g. a decoded video frame) to an RGBA image.
+//
+// The planes are laid out as in image.YCbCr, for an image with bounds rect:
+// the first luma and chroma samples are those of rect.Min.
+// If fullRange is true, samples use the full 0–255 range, as in JPEG;
+// otherwise they use the limited BT.601 range (16–235 luma, 16–240 chroma), as in most video.
+// An error is returned if a plane is too small.
+func YUVPlanarToRGBA(y, u, v []byte, yStride, cStride int, subsampleRatio image.YCbCrSubsampleRatio, rect image.Rectangle, fullRange bool) (*image.RGBA, error) {
+	sx, sy := subsampleShifts(subsampleRatio)
+	w, h := rect.Dx(), rect.Dy()
+	dst := image.NewRGBA(rect)
+	if w <= 0 || h <= 0 {
+		return dst, nil
+	}
+
+	cx0, cy0 := rect.Min.X>>sx, rect.Min.Y>>sy
+	cw := (rect.Max.X+1<<sx-1)>>sx - cx0
+	ch := (rect.Max.Y+1<<sy-1)>>sy - cy0
+	if yStride < w || len(y) < (h-1)*yStride+w {
+		return nil, errors.New("imageutil: luma plane is too small")
+	}
+	if cStride < cw || len(u) < (ch-1)*cStride+cw || len(v) < (ch-1)*cStride+cw {
+		return nil, errors.New("imageutil: chroma plane is too small")
+	}
+
+	convert := color.YCbCrToRGB
+	if !fullRange {
+		convert = limitedYCbCrToRGB
+	}
+
+	for j := 0; j < h; j++ {
+		dst_row := dst.Pix[j*dst.Stride : j*dst.Stride+4*w]
+		y_row := y[j*yStride : j*yStride+w]
+		c_row := ((rect.Min.Y+j)>>sy - cy0) * cStride
+		for i := range w {
+			c := c_row + (rect.Min.X+i)>>sx - cx0
+			r, g, b := convert(y_row[i], u[c], v[c])
+			d := dst_row[4*i : 4*i+4 : 4*i+4]
+			d[0] = r
+			d[1] = g
+			d[2] = b
+			d[3] = 255
+		}
+	}
+	return dst, nil
+}
+
+// RGBAToYUVPlanar converts an RGBA image to planar Y'CbCr (e.g. to feed a video encoder).
+//
+// Planes are tightly packed, and anchored at the origin:
+// the luma plane has yStride == src.Rect.Dx(), and one row for each row of src;
+// both chroma planes have cStride == ceil(src.Rect.Dx() / horizontal subsampling),
+// and ceil(src.Rect.Dy() / vertical subsampling) rows.
+// Chroma is downsampled by averaging each block of subsampled pixels.
+// If fullRange is true, samples use the full 0–255 range, as in JPEG;
+// otherwise they use the limited BT.601 range (16–235 luma, 16–240 chroma), as in most video.
+func RGBAToYUVPlanar(src *image.RGBA, subsampleRatio image.YCbCrSubsampleRatio, fullRange bool) (y, u, v []byte, yStride, cStride int) {
+	img := RGBAToYCbCr(src, subsampleRatio)
+	if !fullRange {
+		// scale luma to 16 + 219/255·c, and chroma to 128 + 224/255·(c-128), rounding
+		for i, c := range img.Y {
+			img.Y[i] = uint8(16 + (219*int(c)+127)/255)
+		}
+		for i, c := range img.Cb {
+			img.Cb[i] = uint8((224*int(c) + 3968 + 127) / 255)
+		}
+		for i, c := range img.Cr {
+			img.Cr[i] = uint8((224*int(c) + 3968 + 127) / 255)
+		}
+	}
+	return img.Y, img.Cb, img.Cr, img.YStride, img.CStride
+}
+
+// limitedYCbCrToRGB converts limited range BT.601 Y'CbCr to RGB.
+func limitedYCbCrToRGB(y, cb, cr uint8) (uint8, uint8, uint8) {
+	yy := (int32(y) - 16) * 76309
+	cb1 := int32(cb) - 128
+	cr1 := int32(cr) - 128
+
+	r := yy + 104597*cr1
+	g := yy - 25675*cb1 - 53279*cr1
+	b := yy + 132201*cb1
+	return clamp16(r), clamp16(g), clamp16(b)
+}
+
+// clamp16 rounds a 16.16 fixed point value, clamped to [0, 255].
+func clamp16(v int32) uint8 {
+	return uint8(max(0, min((v+1<<15)>>16, 255)))
+}
@@ -0,0 +1,148 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_YUVPlanarToRGBA(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	// full range matches image.YCbCr
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	} {
+		img := image.NewYCbCr(image.Rect(0, 0, 16, 16), ratio)
+		fillRandom(img.Y, next())
+		fillRandom(img.Cb, next())
+		fillRandom(img.Cr, next())
+		sub := img.SubImage(image.Rect(1, 3, 14, 15)).(*image.YCbCr)
+
+		dst, err := YUVPlanarToRGBA(sub.Y, sub.Cb, sub.Cr, sub.YStride, sub.CStride, ratio, sub.Rect, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst.Rect != sub.Rect {
+			t.Fatalf("expected: %v, got: %v", sub.Rect, dst.Rect)
+		}
+		for y := sub.Rect.Min.Y; y < sub.Rect.Max.Y; y++ {
+			for x := sub.Rect.Min.X; x < sub.Rect.Max.X; x++ {
+				c := sub.YCbCrAt(x, y)
+				r, g, b := color.YCbCrToRGB(c.Y, c.Cb, c.Cr)
+				if got, want := dst.RGBAAt(x, y), (color.RGBA{r, g, b, 255}); got != want {
+					t.Fatalf("%v: at %dx%d: expected %v, got %v", ratio, x, y, want, got)
+				}
+			}
+		}
+	}
+
+	// limited range: black, white, gray, and BT.601 primaries (rounded, within 1)
+	frame := []struct {
+		y, u, v uint8
+		want    color.RGBA
+	}{
+		{16, 128, 128, color.RGBA{0, 0, 0, 255}},
+		{235, 128, 128, color.RGBA{255, 255, 255, 255}},
+		{126, 128, 128, color.RGBA{128, 128, 128, 255}},
+		{81, 90, 240, color.RGBA{255, 0, 0, 255}},
+		{145, 54, 34, color.RGBA{0, 255, 0, 255}},
+		{41, 240, 110, color.RGBA{0, 0, 255, 255}},
+	}
+	var y, u, v []byte
+	for _, f := range frame {
+		y = append(y, f.y)
+		u = append(u, f.u)
+		v = append(v, f.v)
+	}
+	dst, err := YUVPlanarToRGBA(y, u, v, 2, 2, image.YCbCrSubsampleRatio444, image.Rect(0, 0, 2, 3), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range frame {
+		got := dst.RGBAAt(i%2, i/2)
+		if abs(int(got.R)-int(f.want.R)) > 1 || abs(int(got.G)-int(f.want.G)) > 1 || abs(int(got.B)-int(f.want.B)) > 1 {
+			t.Errorf("%v: expected %v, got %v", f, f.want, got)
+		}
+	}
+
+	// buffers that are too small
+	if _, err := YUVPlanarToRGBA(y[:5], u, v, 2, 2, image.YCbCrSubsampleRatio444, image.Rect(0, 0, 2, 3), false); err == nil {
+		t.Error("expected an error for a short luma plane")
+	}
+	if _, err := YUVPlanarToRGBA(y, u, v, 2, 1, image.YCbCrSubsampleRatio444, image.Rect(0, 0, 2, 3), false); err == nil {
+		t.Error("expected an error for a short chroma stride")
+	}
+	if _, err := YUVPlanarToRGBA(y, u, v[:4], 2, 2, image.YCbCrSubsampleRatio444, image.Rect(0, 0, 2, 3), false); err == nil {
+		t.Error("expected an error for a short chroma plane")
+	}
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func Test_RGBAToYUVPlanar(t *testing.T) {
+	img := image.NewRGBA(image.Rect(2, 3, 21, 18))
+	fillRandom(img.Pix, testSeed(t))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	rect := image.Rect(0, 0, 19, 15)
+
+	for _, full := range []bool{true, false} {
+		y, u, v, yStride, cStride := RGBAToYUVPlanar(img, image.YCbCrSubsampleRatio444, full)
+		if yStride != 19 || cStride != 19 || len(y) != 19*15 || len(u) != len(y) || len(v) != len(y) {
+			t.Fatalf("unexpected layout: %d, %d, %d", yStride, cStride, len(y))
+		}
+		if !full {
+			for _, p := range [][]byte{y, u, v} {
+				for _, c := range p {
+					if c < 16 || c > 240 {
+						t.Fatalf("sample out of limited range: %d", c)
+					}
+				}
+			}
+		}
+
+		dst, err := YUVPlanarToRGBA(y, u, v, yStride, cStride, image.YCbCrSubsampleRatio444, rect, full)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// full range loses less precision
+		tolerance := 2
+		if !full {
+			tolerance = 4
+		}
+		for j := 0; j < 15; j++ {
+			for i := 0; i < 19; i++ {
+				want := img.RGBAAt(i+2, j+3)
+				got := dst.RGBAAt(i, j)
+				if abs(int(got.R)-int(want.R)) > tolerance ||
+					abs(int(got.G)-int(want.G)) > tolerance ||
+					abs(int(got.B)-int(want.B)) > tolerance {
+					t.Fatalf("full range %v: at %dx%d: expected %v, got %v", full, i, j, want, got)
+				}
+			}
+		}
+	}
+
+	// subsampled layout
+	y, u, v, yStride, cStride := RGBAToYUVPlanar(img, image.YCbCrSubsampleRatio420, false)
+	if yStride != 19 || cStride != 10 || len(y) != 19*15 || len(u) != 10*8 || len(v) != 10*8 {
+		t.Fatalf("unexpected layout: %d, %d, %d, %d", yStride, cStride, len(y), len(u))
+	}
+	if _, err := YUVPlanarToRGBA(y, u, v, yStride, cStride, image.YCbCrSubsampleRatio420, rect, false); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package tiles runs per-tile work in parallel, for the filters of the other packages.
+package tiles
+
+import (
+	"image"
+	"runtime"
+	"sync"
+)
+
+// Map splits bounds into tile×tile rectangles (smaller at the right and bottom edges),
+// and calls fn for each, from workers goroutines (GOMAXPROCS, if workers <= 0).
+// It returns when all calls have returned.
+//
+// Calls are concurrent, so fn must only write to memory that belongs to its own tile.
+// Tiles are handed out in row-major order, but may complete in any order.
+func Map(bounds image.Rectangle, tile, workers int, fn func(r image.Rectangle)) {
+	if tile <= 0 {
+		panic("Invalid tile size")
+	}
+	if bounds.Empty() {
+		return
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	cols := (bounds.Dx() + tile - 1) / tile
+	rows := (bounds.Dy() + tile - 1) / tile
+	workers = min(workers, cols*rows)
+
+	rect := func(i int) image.Rectangle {
+		min := bounds.Min.Add(image.Pt(i%cols*tile, i/cols*tile))
+		return image.Rectangle{min, min.Add(image.Pt(tile, tile))}.Intersect(bounds)
+	}
+
+	// serial, no goroutines
+	if workers == 1 {
+		for i := 0; i < cols*rows; i++ {
+			fn(rect(i))
+		}
+		return
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(rect(i))
+			}
+		}()
+	}
+	for i := 0; i < cols*rows; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,38 @@
+package tiles
+
+import (
+	"image"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_Map(t *testing.T) {
+	bounds := image.Rect(-3, 5, 70, 42)
+	for _, workers := range []int{0, 1, 3, 100} {
+		counts := make([]int32, bounds.Dx()*bounds.Dy())
+		var calls int32
+		Map(bounds, 16, workers, func(r image.Rectangle) {
+			atomic.AddInt32(&calls, 1)
+			if !r.In(bounds) || r.Dx() > 16 || r.Dy() > 16 {
+				t.Errorf("unexpected tile %v", r)
+			}
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					atomic.AddInt32(&counts[(y-bounds.Min.Y)*bounds.Dx()+x-bounds.Min.X], 1)
+				}
+			}
+		})
+		if calls != 5*3 {
+			t.Errorf("expected: 15 tiles, got: %d", calls)
+		}
+		for i, n := range counts {
+			if n != 1 {
+				t.Fatalf("pixel %d covered %d times", i, n)
+			}
+		}
+	}
+
+	Map(image.Rectangle{}, 16, 0, func(r image.Rectangle) {
+		t.Errorf("unexpected tile %v", r)
+	})
+}
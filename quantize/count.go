@@ -0,0 +1,66 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+)
+
+// CountColors counts the distinct colors of an image, in 8-bit NRGBA precision,
+// e.g. to decide whether it fits a palette.
+//
+// Counting stops once more than max colors are found:
+// then n is max+1, and exact is false.
+// Fully transparent pixels count as a single color.
+// For paletted images, the number of palette entries in use is returned.
+func CountColors(img image.Image, max int) (n int, exact bool) {
+	bounds := img.Bounds()
+
+	if src, ok := img.(*image.Paletted); ok {
+		var used [256]bool
+		for y := 0; y < bounds.Dy(); y++ {
+			for _, i := range src.Pix[y*src.Stride : y*src.Stride+bounds.Dx()] {
+				if !used[i] {
+					used[i] = true
+					if n++; n > max {
+						return n, false
+					}
+				}
+			}
+		}
+		return n, true
+	}
+
+	seen := map[uint32]struct{}{}
+	add := func(c color.NRGBA) bool {
+		var key uint32
+		if c.A != 0 {
+			key = uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+		}
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			return len(seen) <= max
+		}
+		return true
+	}
+
+	if src, ok := img.(*image.NRGBA); ok {
+		for y := 0; y < bounds.Dy(); y++ {
+			row := src.Pix[y*src.Stride : y*src.Stride+4*bounds.Dx()]
+			for i := 0; i < len(row); i += 4 {
+				if !add(color.NRGBA{row[i], row[i+1], row[i+2], row[i+3]}) {
+					return len(seen), false
+				}
+			}
+		}
+		return len(seen), true
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !add(color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)) {
+				return len(seen), false
+			}
+		}
+	}
+	return len(seen), true
+}
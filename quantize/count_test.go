@@ -0,0 +1,50 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"testing"
+)
+
+func Test_CountColors(t *testing.T) {
+	colors := []color.NRGBA{
+		{255, 0, 0, 255},
+		{0, 255, 0, 255},
+		{0, 0, 255, 255},
+		{0, 0, 255, 128},
+		{10, 20, 30, 0},
+		{30, 20, 10, 0}, // transparent, the same as above
+	}
+
+	img := image.NewNRGBA(image.Rect(3, 3, 19, 19))
+	for y := 3; y < 19; y++ {
+		for x := 3; x < 19; x++ {
+			img.SetNRGBA(x, y, colors[(x*y)%len(colors)])
+		}
+	}
+
+	for _, img := range []image.Image{img, &wrapper{img}} {
+		if n, exact := CountColors(img, 256); n != 5 || !exact {
+			t.Errorf("%T: expected: 5, got: %d, %v", img, n, exact)
+		}
+		if n, exact := CountColors(img, 5); n != 5 || !exact {
+			t.Errorf("%T: expected: 5, got: %d, %v", img, n, exact)
+		}
+		if n, exact := CountColors(img, 3); n != 4 || exact {
+			t.Errorf("%T: expected: 4, inexact, got: %d, %v", img, n, exact)
+		}
+	}
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 16, 16), palette.WebSafe)
+	for i := range paletted.Pix {
+		paletted.Pix[i] = uint8(i % 7)
+	}
+	if n, exact := CountColors(paletted, 256); n != 7 || !exact {
+		t.Errorf("expected: 7, got: %d, %v", n, exact)
+	}
+}
+
+type wrapper struct {
+	image.Image
+}
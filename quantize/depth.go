@@ -0,0 +1,96 @@
+package quantize
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// ReduceBitDepth quantizes each color channel of an image to bits bits (1 to 8),
+// for retro or LCD effects, or to pre-process an image for a small palette.
+//
+// Each 8-bit value v is mapped to the nearest of 2^bits evenly spaced levels:
+// round(v/step)*step, with step = 255/(2^bits-1).
+// Alpha is preserved. The result is anchored at the origin.
+func ReduceBitDepth(img image.Image, bits int) *image.NRGBA {
+	return reduceBitDepth(img, bits, false)
+}
+
+// ReduceBitDepthDithered quantizes each color channel of an image to bits bits (1 to 8),
+// like ReduceBitDepth, using Floyd–Steinberg error diffusion.
+//
+// Errors are diffused in linear light, so the average brightness of areas is preserved.
+// Rows are scanned in alternating directions to avoid directional artifacts.
+func ReduceBitDepthDithered(img image.Image, bits int) *image.NRGBA {
+	return reduceBitDepth(img, bits, true)
+}
+
+func reduceBitDepth(img image.Image, bits int, dither bool) *image.NRGBA {
+	if bits < 1 || bits > 8 {
+		panic("Invalid bit depth")
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Rect, img, bounds.Min, draw.Src)
+
+	step := 255 / float64(int(1)<<bits-1)
+	quantize := func(v float64) uint8 {
+		return uint8(math.Round(math.Round(v/step) * step))
+	}
+
+	if !dither {
+		for i := range dst.Pix {
+			if i%4 != 3 {
+				dst.Pix[i] = quantize(float64(dst.Pix[i]))
+			}
+		}
+		return dst
+	}
+
+	linear := func(v uint8) float32 {
+		return float32(imageutil.SRGB8ToLinear(v)) / 65535
+	}
+
+	// error buffers for the current and next rows, with a pixel of padding on each side
+	cur := make([][3]float32, w+2)
+	nxt := make([][3]float32, w+2)
+
+	for y := 0; y < h; y++ {
+		x0, x1, dx := 0, w, 1
+		if y%2 != 0 {
+			x0, x1, dx = w-1, -1, -1
+		}
+
+		for x := x0; x != x1; x += dx {
+			p := dst.Pix[y*dst.Stride+4*x:][:3]
+			e := &cur[x+1]
+
+			var q [3]float32
+			for i := range p {
+				c := max(0, min(linear(p[i])+e[i], 1))
+				v := imageutil.LinearToSRGB16(uint16(math.Round(65535 * float64(c))))
+				p[i] = quantize(float64(v) / 257)
+				q[i] = c - linear(p[i])
+			}
+
+			diffuse := func(e *[3]float32, f float32) {
+				for i := range e {
+					e[i] += q[i] * f
+				}
+			}
+			diffuse(&cur[x+1+dx], 7.0/16)
+			diffuse(&nxt[x+1-dx], 3.0/16)
+			diffuse(&nxt[x+1], 5.0/16)
+			diffuse(&nxt[x+1+dx], 1.0/16)
+		}
+
+		cur, nxt = nxt, cur
+		clear(nxt)
+	}
+
+	return dst
+}
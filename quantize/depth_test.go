@@ -0,0 +1,77 @@
+package quantize
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+func Test_ReduceBitDepth(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(3, 3, 67, 35))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+
+	for _, reduce := range []func(image.Image, int) *image.NRGBA{ReduceBitDepth, ReduceBitDepthDithered} {
+		// 8 bits is the identity
+		dst := reduce(img, 8)
+		if dst.Rect != image.Rect(0, 0, 64, 32) {
+			t.Fatalf("unexpected bounds: %v", dst.Rect)
+		}
+		if string(dst.Pix) != string(img.Pix) {
+			t.Error("expected no change for 8 bits")
+		}
+
+		// 1 bit has two levels, and preserves alpha
+		dst = reduce(img, 1)
+		for i, v := range dst.Pix {
+			if i%4 == 3 {
+				if v != img.Pix[i] {
+					t.Fatalf("alpha changed at %d", i)
+				}
+			} else if v != 0 && v != 255 {
+				t.Fatalf("unexpected level %d at %d", v, i)
+			}
+		}
+
+		// 2 bits has four levels
+		dst = reduce(img, 2)
+		for i, v := range dst.Pix {
+			if i%4 != 3 && v != 0 && v != 85 && v != 170 && v != 255 {
+				t.Fatalf("unexpected level %d at %d", v, i)
+			}
+		}
+	}
+
+	// without dithering, levels are the nearest: round(v/85)*85
+	dst := ReduceBitDepth(img, 2)
+	for i, v := range dst.Pix {
+		if i%4 != 3 && v != uint8(85*math.Round(float64(img.Pix[i])/85)) {
+			t.Fatalf("unexpected level %d for %d", v, img.Pix[i])
+		}
+	}
+}
+
+func Test_ReduceBitDepthDithered(t *testing.T) {
+	// half the light of white: nearest is white, dithering mixes black and white
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = 188
+	}
+
+	if dst := ReduceBitDepth(img, 1); dst.Pix[0] != 255 {
+		t.Errorf("expected white, got %d", dst.Pix[0])
+	}
+
+	dst := ReduceBitDepthDithered(img, 1)
+	var light float64
+	for i := 0; i < len(dst.Pix); i += 4 {
+		light += float64(imageutil.SRGB8ToLinear(dst.Pix[i])) / 65535
+	}
+	light /= 32 * 32
+	if math.Abs(light-0.5) > 0.01 {
+		t.Errorf("expected half the light, got %v", light)
+	}
+}
@@ -0,0 +1,111 @@
+// Package quantize reduces the colors of images, for palette based formats like GIF and PNG-8.
+//
+// The package works with the Image interface described in the image package.
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// ToPaletteDithered maps an image to a palette using Floyd–Steinberg error diffusion.
+//
+// Colors are matched, and errors diffused, in linear light with premultiplied alpha,
+// so palettes with transparent entries are handled naturally.
+// Rows are scanned in alternating directions to avoid directional artifacts.
+// The result is anchored at the origin.
+func ToPaletteDithered(img image.Image, p color.Palette) *image.Paletted {
+	return toPalette(img, p, true)
+}
+
+// toPalette maps an image to a palette, optionally diffusing errors.
+func toPalette(img image.Image, p color.Palette, dither bool) *image.Paletted {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewPaletted(image.Rect(0, 0, w, h), p)
+	if len(p) == 0 {
+		return dst
+	}
+
+	src := image.NewNRGBA(dst.Rect)
+	draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+
+	pal := make([][4]float32, len(p))
+	for i, c := range p {
+		pal[i] = toLinear(color.NRGBAModel.Convert(c).(color.NRGBA))
+	}
+
+	// error buffers for the current and next rows, with a pixel of padding on each side
+	cur := make([][4]float32, w+2)
+	nxt := make([][4]float32, w+2)
+
+	for y := 0; y < h; y++ {
+		x0, x1, dx := 0, w, 1
+		if y%2 != 0 {
+			x0, x1, dx = w-1, -1, -1
+		}
+
+		for x := x0; x != x1; x += dx {
+			c := toLinear(src.NRGBAAt(x, y))
+			e := &cur[x+1]
+			for i := range c {
+				c[i] = max(0, min(c[i]+e[i], 1))
+			}
+
+			idx := nearest(pal, c)
+			dst.Pix[y*dst.Stride+x] = uint8(idx)
+			if !dither {
+				continue
+			}
+
+			var q [4]float32
+			for i := range q {
+				q[i] = c[i] - pal[idx][i]
+			}
+			diffuse := func(e *[4]float32, f float32) {
+				for i := range e {
+					e[i] += q[i] * f
+				}
+			}
+			diffuse(&cur[x+1+dx], 7.0/16)
+			diffuse(&nxt[x+1-dx], 3.0/16)
+			diffuse(&nxt[x+1], 5.0/16)
+			diffuse(&nxt[x+1+dx], 1.0/16)
+		}
+
+		cur, nxt = nxt, cur
+		clear(nxt)
+	}
+
+	return dst
+}
+
+// toLinear converts a color to linear light, with premultiplied alpha, in [0, 1].
+func toLinear(c color.NRGBA) [4]float32 {
+	a := float32(c.A) / 255
+	return [4]float32{
+		a * float32(imageutil.SRGB8ToLinear(c.R)) / 65535,
+		a * float32(imageutil.SRGB8ToLinear(c.G)) / 65535,
+		a * float32(imageutil.SRGB8ToLinear(c.B)) / 65535,
+		a,
+	}
+}
+
+// nearest returns the index of the palette entry closest to a color.
+func nearest(pal [][4]float32, c [4]float32) int {
+	var best int
+	var dist float32
+	for i, p := range pal {
+		var d float32
+		for j := range p {
+			d += (p[j] - c[j]) * (p[j] - c[j])
+		}
+		if i == 0 || d < dist {
+			best, dist = i, d
+		}
+	}
+	return best
+}
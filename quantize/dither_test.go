@@ -0,0 +1,74 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func Test_ToPaletteDithered(t *testing.T) {
+	// a smooth gray gradient
+	img := image.NewGray(image.Rect(5, 5, 261, 37))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 256; x++ {
+			img.Pix[y*img.Stride+x] = uint8(x)
+		}
+	}
+
+	p := color.Palette{color.Black, color.Gray{85}, color.Gray{170}, color.White}
+
+	dithered := ToPaletteDithered(img, p)
+	if r := image.Rect(0, 0, 256, 32); dithered.Rect != r {
+		t.Fatalf("expected: %v, got: %v", r, dithered.Rect)
+	}
+
+	nearest := image.NewPaletted(dithered.Rect, p)
+	draw.Draw(nearest, nearest.Rect, img, img.Rect.Min, draw.Src)
+
+	// perceived error: compare 8×8 block averages in linear light
+	perceived := func(dst *image.Paletted) (err float64) {
+		for by := 0; by < 32; by += 8 {
+			for bx := 0; bx < 256; bx += 8 {
+				var s, d float64
+				for y := by; y < by+8; y++ {
+					for x := bx; x < bx+8; x++ {
+						s += float64(toLinear(color.NRGBAModel.Convert(img.GrayAt(x+5, y+5)).(color.NRGBA))[0])
+						d += float64(toLinear(color.NRGBAModel.Convert(dst.At(x, y)).(color.NRGBA))[0])
+					}
+				}
+				err += math.Abs(s-d) / 64
+			}
+		}
+		return err
+	}
+
+	if e0, e1 := perceived(nearest), perceived(dithered); e1 >= e0/4 {
+		t.Errorf("dithering didn't reduce error: %v, %v", e0, e1)
+	}
+}
+
+func Test_ToPaletteDithered_transparent(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+
+	p := color.Palette{color.Transparent, color.NRGBA{255, 0, 0, 255}, color.White}
+
+	dst := ToPaletteDithered(img, p)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			exp := uint8(0)
+			if x < 8 {
+				exp = 1
+			}
+			if i := dst.ColorIndexAt(x, y); i != exp {
+				t.Fatalf("at %dx%d, expected: %d, got: %d", x, y, exp, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// ToIndexed converts an image to a paletted image, with an adaptive palette
+// of at most maxColors entries (up to 256), built by median cut.
+//
+// If the image has fully transparent pixels, one palette entry is reserved for them,
+// as required by GIF and useful for PNG-8
+// (unless maxColors is 1, and the image has other colors, which then get the only entry).
+// Colors are mapped with Floyd–Steinberg error diffusion if dither is true,
+// and to the nearest palette entry otherwise.
+// The result is anchored at the origin.
+func ToIndexed(img image.Image, maxColors int, dither bool) *image.Paletted {
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Rect, img, bounds.Min, draw.Src)
+
+	maxColors = max(1, min(maxColors, 256))
+
+	var transparent bool
+	hist := map[color.NRGBA]int{}
+	for i := 0; i < len(src.Pix); i += 4 {
+		c := color.NRGBA{src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3]}
+		if c.A == 0 {
+			transparent = true
+		} else {
+			hist[c]++
+		}
+	}
+
+	var p color.Palette
+	if transparent && (maxColors > 1 || len(hist) == 0) {
+		p = append(p, color.NRGBA{})
+		maxColors--
+	}
+	if len(hist) > 0 {
+		p = append(p, medianCut(hist, maxColors)...)
+	}
+
+	return toPalette(src, p, dither)
+}
+
+// colorCount is a distinct color, and the number of pixels that have it.
+type colorCount struct {
+	c [4]uint8
+	n int
+}
+
+// medianCut builds a palette of at most n colors from a color histogram.
+func medianCut(hist map[color.NRGBA]int, n int) color.Palette {
+	colors := make([]colorCount, 0, len(hist))
+	for c, n := range hist {
+		colors = append(colors, colorCount{[4]uint8{c.R, c.G, c.B, c.A}, n})
+	}
+	// map iteration order is random; sort for deterministic results
+	sort.Slice(colors, func(i, j int) bool {
+		a, b := colors[i].c, colors[j].c
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	boxes := [][]colorCount{colors}
+	for len(boxes) < n {
+		// split the box with the widest channel range
+		box, channel, width := -1, 0, 0
+		for i, b := range boxes {
+			if len(b) < 2 {
+				continue
+			}
+			ch, w := widestChannel(b)
+			if w > width {
+				box, channel, width = i, ch, w
+			}
+		}
+		if box < 0 {
+			break
+		}
+
+		b := boxes[box]
+		sort.SliceStable(b, func(i, j int) bool { return b[i].c[channel] < b[j].c[channel] })
+
+		// split at the pixel median, leaving at least one color on each side
+		var total, acc int
+		for _, c := range b {
+			total += c.n
+		}
+		split := 1
+		for i, c := range b[:len(b)-1] {
+			if acc += c.n; 2*acc >= total {
+				split = i + 1
+				break
+			}
+		}
+
+		boxes[box] = b[:split:split]
+		boxes = append(boxes, b[split:])
+	}
+
+	p := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		var sum [4]int
+		var total int
+		for _, c := range b {
+			for k := range sum {
+				sum[k] += int(c.c[k]) * c.n
+			}
+			total += c.n
+		}
+		var avg [4]uint8
+		for k := range avg {
+			avg[k] = uint8((sum[k] + total/2) / total)
+		}
+		p[i] = color.NRGBA{avg[0], avg[1], avg[2], avg[3]}
+	}
+	return p
+}
+
+// widestChannel returns the channel with the widest range of values in a box, and that range.
+func widestChannel(box []colorCount) (channel, width int) {
+	lo := box[0].c
+	hi := box[0].c
+	for _, c := range box[1:] {
+		for k := range lo {
+			lo[k] = min(lo[k], c.c[k])
+			hi[k] = max(hi[k], c.c[k])
+		}
+	}
+	for k := range lo {
+		if w := int(hi[k]) - int(lo[k]); w > width {
+			channel, width = k, w
+		}
+	}
+	return channel, width
+}
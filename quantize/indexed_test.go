@@ -0,0 +1,93 @@
+package quantize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ToIndexed(t *testing.T) {
+	// a color gradient, with a transparent band in the middle
+	img := image.NewNRGBA(image.Rect(3, 3, 259, 35))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 256; x++ {
+			c := color.NRGBA{uint8(x), uint8(255 - x), uint8(8 * y), 255}
+			if 96 <= x && x < 160 {
+				c.A = 0
+			}
+			img.SetNRGBA(x+3, y+3, c)
+		}
+	}
+
+	for _, dither := range []bool{false, true} {
+		dst := ToIndexed(img, 16, dither)
+		if r := image.Rect(0, 0, 256, 32); dst.Rect != r {
+			t.Fatalf("expected: %v, got: %v", r, dst.Rect)
+		}
+		if len(dst.Palette) > 16 {
+			t.Fatalf("palette has %d colors", len(dst.Palette))
+		}
+
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 256; x++ {
+				_, _, _, a := dst.At(x, y).RGBA()
+				if transparent := 96 <= x && x < 160; transparent != (a == 0) {
+					t.Fatalf("dither %v, at (%d, %d): alpha %#x", dither, x, y, a)
+				}
+			}
+		}
+	}
+}
+
+func Test_ToIndexed_one(t *testing.T) {
+	// a single color, and transparency
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix)/2; i += 4 {
+		copy(img.Pix[i:], []uint8{200, 100, 50, 255})
+	}
+
+	for _, maxColors := range []int{0, 1} {
+		dst := ToIndexed(img, maxColors, false)
+		if len(dst.Palette) != 1 {
+			t.Fatalf("expected 1 color, got %d", len(dst.Palette))
+		}
+		if c := dst.Palette[0]; c != (color.NRGBA{200, 100, 50, 255}) {
+			t.Errorf("expected the opaque color, got %v", c)
+		}
+	}
+	if dst := ToIndexed(img, 2, false); len(dst.Palette) != 2 {
+		t.Errorf("expected 2 colors, got %d", len(dst.Palette))
+	}
+
+	// only transparency
+	dst := ToIndexed(image.NewNRGBA(image.Rect(0, 0, 4, 4)), 1, false)
+	if len(dst.Palette) != 1 || dst.Palette[0] != (color.NRGBA{}) {
+		t.Errorf("expected a transparent entry, got %v", dst.Palette)
+	}
+}
+
+func Test_ToIndexed_few(t *testing.T) {
+	// images with fewer colors than the limit are reproduced exactly
+	p := color.Palette{
+		color.NRGBA{255, 0, 0, 255},
+		color.NRGBA{0, 255, 0, 255},
+		color.NRGBA{0, 0, 255, 128},
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 9, 9))
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := p[i/4%len(p)].(color.NRGBA)
+		copy(img.Pix[i:], []uint8{c.R, c.G, c.B, c.A})
+	}
+
+	dst := ToIndexed(img, 256, true)
+	if len(dst.Palette) != len(p) {
+		t.Fatalf("expected %d colors, got %d", len(p), len(dst.Palette))
+	}
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 9; x++ {
+			if got, want := dst.At(x, y), img.NRGBAAt(x, y); got != want {
+				t.Fatalf("at (%d, %d): expected %v, got %v", x, y, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package resize
+
+import (
+	"image"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// ResizeGaussian scales an image to new width and height, sampling it through a Gaussian filter.
+//
+// Sigma is the standard deviation of the filter, in destination pixels (0.5 is a good default).
+// When downscaling, the filter widens with the reduction factor, which avoids aliasing.
+// If one of the parameters w or h is set to 0, its size will be calculated so that
+// the aspect ratio is that of the originating image.
+//
+// The filter is separable, applied in linear light with premultiplied alpha,
+// and clamps to the edges of the image.
+func ResizeGaussian(src image.Image, w, h int, sigma float64) *image.NRGBA {
+	bounds := src.Bounds()
+	w, h = resizeSize(bounds, w, h)
+	if sigma <= 0 {
+		sigma = 0.5
+	}
+
+	if bounds.Empty() {
+		return image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+
+	in := imageutil.ToLinearFloat(src)
+	sw, sh := bounds.Dx(), bounds.Dy()
+
+	xo, xw := gaussianWeights(w, sw, sigma)
+	yo, yw := gaussianWeights(h, sh, sigma)
+	dst := imageutil.NewLinearRGBA(image.Rect(0, 0, w, h))
+	dst.Pix = resample(in.Pix, sw, sh, w, h, xo, xw, yo, yw)
+
+	return imageutil.FromLinearFloat(dst)
+}
+
+// gaussianWeights computes, for each of the dst samples, the src samples (clamped) and weights to use.
+func gaussianWeights(dst, src int, sigma float64) ([][]int, [][]float32) {
+	scale := float64(src) / float64(dst)
+	if scale > 1 {
+		sigma *= scale
+	}
+	radius := int(math.Ceil(3 * sigma))
+
+	offset := make([][]int, dst)
+	weights := make([][]float32, dst)
+	for i := range offset {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := int(math.Floor(center)) - radius
+		end := int(math.Ceil(center)) + radius
+
+		var sum float64
+		ws := make([]float64, 0, end-start+1)
+		for j := start; j <= end; j++ {
+			d := float64(j) - center
+			w := math.Exp(-d * d / (2 * sigma * sigma))
+			ws = append(ws, w)
+			sum += w
+		}
+
+		offset[i] = make([]int, len(ws))
+		weights[i] = make([]float32, len(ws))
+		for k, w := range ws {
+			j := start + k
+			if j < 0 {
+				j = 0
+			}
+			if j >= src {
+				j = src - 1
+			}
+			offset[i][k] = j
+			weights[i][k] = float32(w / sum)
+		}
+	}
+	return offset, weights
+}
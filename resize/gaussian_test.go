@@ -0,0 +1,71 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_ResizeGaussianBounds(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 10, 210, 110))
+
+	if m := ResizeGaussian(src, 50, 0, 0.5); m.Bounds() != image.Rect(0, 0, 50, 25) {
+		t.Errorf("unexpected bounds: %v", m.Bounds())
+	}
+	if m := ResizeGaussian(src, 0, 50, 0.5); m.Bounds() != image.Rect(0, 0, 100, 50) {
+		t.Errorf("unexpected bounds: %v", m.Bounds())
+	}
+	if m := ResizeGaussian(src, 0, 0, 0.5); m.Bounds() != image.Rect(0, 0, 200, 100) {
+		t.Errorf("unexpected bounds: %v", m.Bounds())
+	}
+}
+
+func Test_ResizeGaussianSameColor(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{200, 100, 50, 128})
+		}
+	}
+
+	m := ResizeGaussian(src, 7, 5, 0.5)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 7; x++ {
+			if c := m.NRGBAAt(x, y); c != (color.NRGBA{200, 100, 50, 128}) {
+				t.Fatalf("at %dx%d: unexpected color %v", x, y, c)
+			}
+		}
+	}
+}
+
+func Test_ResizeGaussianNoise(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	rnd := rand.New(rand.NewSource(1))
+	for i := range src.Pix {
+		if i%4 == 3 {
+			src.Pix[i] = 0xff
+		} else {
+			src.Pix[i] = uint8(rnd.Int63())
+		}
+	}
+
+	energy := func(img image.Image) (sum float64) {
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X + 1; x < b.Max.X; x++ {
+				r0, _, _, _ := img.At(x-1, y).RGBA()
+				r1, _, _, _ := img.At(x, y).RGBA()
+				d := float64(r1>>8) - float64(r0>>8)
+				sum += d * d
+			}
+		}
+		return sum
+	}
+
+	near := energy(Resize(64, 64, src, NearestNeighbor))
+	gaus := energy(ResizeGaussian(src, 64, 64, 0.5))
+	if gaus >= near/4 {
+		t.Errorf("high-frequency energy, nearest: %v, gaussian: %v", near, gaus)
+	}
+}
@@ -0,0 +1,596 @@
+package resize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"math"
+)
+
+// DecodeJPEGScaled decodes a JPEG image, downscaled so that neither side is larger than maxDim.
+//
+// Baseline images are decoded at 1/8, 1/4, 1/2 or full size, whichever is the smallest
+// with a side at least maxDim long, by computing a smaller inverse DCT from the lower frequencies
+// of each block.
+// The result is then area downscaled (see ResizeWith) to fit maxDim, preserving the aspect ratio.
+// Other images (e.g. progressive, or CMYK) are fully decoded, and area downscaled.
+//
+// Images that already fit maxDim, or if maxDim is not positive, are decoded at full size, by image/jpeg.
+// If no further downscale is needed, the decoded image (e.g. an *image.YCbCr) is returned,
+// otherwise an *image.NRGBA; either way, the result is anchored at the origin.
+func DecodeJPEGScaled(r io.Reader, maxDim int) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := jpegDecoder{data: data, maxDim: maxDim}
+	img, err := d.decode()
+	if err != nil {
+		// let image/jpeg handle (and report errors for) anything unsupported
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		d.width, d.height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	bounds := img.Bounds()
+	if maxDim <= 0 || max(bounds.Dx(), bounds.Dy()) <= maxDim {
+		return img, nil
+	}
+	w, h := d.width, d.height
+	if w >= h {
+		w, h = maxDim, max(1, int(0.5+float64(h*maxDim)/float64(w)))
+	} else {
+		w, h = max(1, int(0.5+float64(w*maxDim)/float64(h))), maxDim
+	}
+	return ResizeWith(img, w, h, ResizeOptions{Filter: FilterArea}), nil
+}
+
+var errJPEGUnsupported = errors.New("resize: unsupported JPEG")
+
+// jpegUnzig maps the zig-zag order of DCT coefficients to their natural order.
+var jpegUnzig = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// jpegLookahead is the number of bits used to decode Huffman codes with a table lookup.
+const jpegLookahead = 9
+
+type jpegHuffman struct {
+	maxcode [17]int32
+	valptr  [17]int32
+	mincode [17]int32
+	value   []byte
+	// the value<<8 | length of codes up to jpegLookahead bits long,
+	// indexed by the next jpegLookahead bits; 0 for longer codes
+	lookup [1 << jpegLookahead]uint16
+}
+
+type jpegComponent struct {
+	id, h, v, tq uint8
+	bw, bh       int // the size of decoded blocks
+	pix          []uint8
+	stride       int
+}
+
+// jpegDecoder is a baseline JPEG decoder, that decodes at a reduced scale.
+type jpegDecoder struct {
+	data   []byte
+	maxDim int
+
+	width, height int
+	scale         int // the size of decoded luma blocks: 1, 2, 4 or 8
+	hmax, vmax    int
+	comps         []jpegComponent
+	quant         [4][64]int32 // in natural order
+	huff          [2][4]*jpegHuffman
+	restart       int
+	adobe         bool
+	img           image.Image
+	idct          [9]*[8][8]float64 // [size][x][u]
+}
+
+func (d *jpegDecoder) decode() (image.Image, error) {
+	data := d.data
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errJPEGUnsupported
+	}
+
+	for p := 2; ; {
+		for p < len(data) && data[p] != 0xff {
+			p++
+		}
+		for p < len(data) && data[p] == 0xff {
+			p++
+		}
+		if p >= len(data) {
+			break
+		}
+		marker := data[p]
+		p++
+
+		switch {
+		case marker == 0xd9: // EOI
+			return d.image()
+		case marker == 0x01 || 0xd0 <= marker && marker <= 0xd7:
+			continue
+		}
+
+		if p+2 > len(data) {
+			break
+		}
+		n := int(binary.BigEndian.Uint16(data[p:]))
+		if n < 2 || p+n > len(data) {
+			break
+		}
+		seg := data[p+2 : p+n]
+		p += n
+
+		var err error
+		switch marker {
+		case 0xc0, 0xc1: // SOF0, SOF1
+			err = d.frame(seg)
+		case 0xc4: // DHT
+			err = d.huffman(seg)
+		case 0xdb: // DQT
+			err = d.quantization(seg)
+		case 0xdd: // DRI
+			if len(seg) < 2 {
+				return nil, errJPEGUnsupported
+			}
+			d.restart = int(binary.BigEndian.Uint16(seg))
+		case 0xda: // SOS
+			p, err = d.scan(seg, p)
+		case 0xee: // APP14
+			d.adobe = d.adobe || len(seg) >= 12 && string(seg[:5]) == "Adobe"
+		case 0xc2, 0xc3, 0xc5, 0xc6, 0xc7, 0xc9, 0xca, 0xcb, 0xcd, 0xce, 0xcf:
+			// progressive, lossless, hierarchical or arithmetic coding
+			err = errJPEGUnsupported
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// truncated, without an EOI marker
+	return nil, errJPEGUnsupported
+}
+
+// image returns the decoded image, cropped to its scaled size.
+func (d *jpegDecoder) image() (image.Image, error) {
+	if d.img == nil {
+		return nil, errJPEGUnsupported
+	}
+	r := image.Rect(0, 0, (d.width*d.scale+7)/8, (d.height*d.scale+7)/8)
+	return d.img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}).SubImage(r), nil
+}
+
+func (d *jpegDecoder) frame(seg []byte) error {
+	if d.comps != nil || len(seg) < 6 || seg[0] != 8 {
+		return errJPEGUnsupported
+	}
+	d.height = int(binary.BigEndian.Uint16(seg[1:]))
+	d.width = int(binary.BigEndian.Uint16(seg[3:]))
+	n := int(seg[5])
+	if d.width == 0 || d.height == 0 || n != 1 && n != 3 || len(seg) < 6+3*n {
+		return errJPEGUnsupported
+	}
+
+	d.comps = make([]jpegComponent, n)
+	d.hmax, d.vmax = 1, 1
+	for i := range d.comps {
+		c := &d.comps[i]
+		c.id, c.h, c.v, c.tq = seg[6+3*i], seg[7+3*i]>>4, seg[7+3*i]&15, seg[8+3*i]
+		if c.h < 1 || c.h > 4 || c.v < 1 || c.v > 4 || c.tq > 3 {
+			return errJPEGUnsupported
+		}
+		if n == 1 {
+			// a single component is never interleaved
+			c.h, c.v = 1, 1
+		}
+		d.hmax, d.vmax = max(d.hmax, int(c.h)), max(d.vmax, int(c.v))
+	}
+
+	// the smallest scale that still has a side at least maxDim long
+	d.scale = 8
+	if d.maxDim > 0 {
+		for s := 1; s < 8; s *= 2 {
+			if max(d.width*s+7, d.height*s+7)/8 >= d.maxDim {
+				d.scale = s
+				break
+			}
+		}
+	}
+	// full size is left to image/jpeg
+	if d.scale == 8 {
+		return errJPEGUnsupported
+	}
+
+	// decode into planes that cover every MCU
+	mx := (d.width + 8*d.hmax - 1) / (8 * d.hmax)
+	my := (d.height + 8*d.vmax - 1) / (8 * d.vmax)
+	r := image.Rect(0, 0, mx*d.hmax*d.scale, my*d.vmax*d.scale)
+	if n == 1 {
+		img := image.NewGray(r)
+		c := &d.comps[0]
+		c.pix, c.stride = img.Pix, img.Stride
+		c.bw, c.bh = d.scale, d.scale
+		d.img = img
+		return nil
+	}
+
+	// the component IDs of (non-Adobe) RGB images
+	if d.adobe || d.comps[0].id == 'R' && d.comps[1].id == 'G' && d.comps[2].id == 'B' {
+		return errJPEGUnsupported
+	}
+	if d.comps[1].h != 1 || d.comps[1].v != 1 || d.comps[2].h != 1 || d.comps[2].v != 1 {
+		return errJPEGUnsupported
+	}
+	if d.hmax == 3 || d.vmax > 2 {
+		return errJPEGUnsupported
+	}
+	// when scaled down, subsampled chroma is decoded at a larger scale, up to full size
+	for i := range d.comps {
+		c := &d.comps[i]
+		c.bw = min(8, d.scale*d.hmax/int(c.h))
+		c.bh = min(8, d.scale*d.vmax/int(c.v))
+	}
+	fh := d.comps[0].bw * d.hmax / d.comps[1].bw
+	fv := d.comps[0].bh * d.vmax / d.comps[1].bh
+
+	var ratio image.YCbCrSubsampleRatio
+	switch fh<<4 | fv {
+	case 0x11:
+		ratio = image.YCbCrSubsampleRatio444
+	case 0x21:
+		ratio = image.YCbCrSubsampleRatio422
+	case 0x22:
+		ratio = image.YCbCrSubsampleRatio420
+	case 0x12:
+		ratio = image.YCbCrSubsampleRatio440
+	case 0x41:
+		ratio = image.YCbCrSubsampleRatio411
+	case 0x42:
+		ratio = image.YCbCrSubsampleRatio410
+	default:
+		return errJPEGUnsupported
+	}
+	img := image.NewYCbCr(r, ratio)
+	d.comps[0].pix, d.comps[0].stride = img.Y, img.YStride
+	d.comps[1].pix, d.comps[1].stride = img.Cb, img.CStride
+	d.comps[2].pix, d.comps[2].stride = img.Cr, img.CStride
+	d.img = img
+	return nil
+}
+
+func (d *jpegDecoder) quantization(seg []byte) error {
+	for len(seg) > 0 {
+		pq, tq := seg[0]>>4, seg[0]&15
+		if pq > 1 || tq > 3 || len(seg) < 1+64<<pq {
+			return errJPEGUnsupported
+		}
+		for k := range 64 {
+			v := int32(seg[1+k])
+			if pq == 1 {
+				v = int32(binary.BigEndian.Uint16(seg[1+2*k:]))
+			}
+			d.quant[tq][jpegUnzig[k]] = v
+		}
+		seg = seg[1+64<<pq:]
+	}
+	return nil
+}
+
+func (d *jpegDecoder) huffman(seg []byte) error {
+	for len(seg) > 0 {
+		if len(seg) < 17 || seg[0]>>4 > 1 || seg[0]&15 > 3 {
+			return errJPEGUnsupported
+		}
+		class, id := seg[0]>>4, seg[0]&15
+
+		var h jpegHuffman
+		total := 0
+		for _, n := range seg[1:17] {
+			total += int(n)
+		}
+		if total > 256 || len(seg) < 17+total {
+			return errJPEGUnsupported
+		}
+		h.value = seg[17 : 17+total]
+
+		// canonical codes, as in Annex C and F.2.2.3 of the spec
+		var code, k int32
+		for l := 1; l <= 16; l++ {
+			n := int32(seg[l])
+			h.valptr[l] = k
+			h.mincode[l] = code
+			if l <= jpegLookahead {
+				for i := range n {
+					c := code + i
+					if c >= 1<<l {
+						break // invalid table
+					}
+					e := uint16(h.value[k+i])<<8 | uint16(l)
+					for j := c << (jpegLookahead - l); j < (c+1)<<(jpegLookahead-l); j++ {
+						h.lookup[j] = e
+					}
+				}
+			}
+			code += n
+			k += n
+			if n == 0 {
+				h.maxcode[l] = -1
+			} else {
+				h.maxcode[l] = code - 1
+			}
+			code <<= 1
+		}
+
+		d.huff[class][id] = &h
+		seg = seg[17+total:]
+	}
+	return nil
+}
+
+// scan decodes the entropy coded data after an SOS segment at p,
+// and returns the position that follows it.
+func (d *jpegDecoder) scan(seg []byte, p int) (int, error) {
+	if d.comps == nil || len(seg) < 1 {
+		return p, errJPEGUnsupported
+	}
+	n := int(seg[0])
+	if n < 1 || n > len(d.comps) || len(seg) < 1+2*n+3 {
+		return p, errJPEGUnsupported
+	}
+
+	type scanComponent struct {
+		*jpegComponent
+		dc, ac *jpegHuffman
+		pred   int32
+	}
+	comps := make([]scanComponent, n)
+	for i := range comps {
+		id, tables := seg[1+2*i], seg[2+2*i]
+		for j := range d.comps {
+			if d.comps[j].id == id {
+				comps[i].jpegComponent = &d.comps[j]
+			}
+		}
+		if tables>>4 > 3 || tables&15 > 3 {
+			return p, errJPEGUnsupported
+		}
+		comps[i].dc = d.huff[0][tables>>4]
+		comps[i].ac = d.huff[1][tables&15]
+		if comps[i].jpegComponent == nil || comps[i].dc == nil || comps[i].ac == nil {
+			return p, errJPEGUnsupported
+		}
+	}
+
+	// a non-interleaved scan has MCUs of a single block,
+	// that cover the component, which may be smaller than the planes
+	mx := (d.width + 8*d.hmax - 1) / (8 * d.hmax)
+	my := (d.height + 8*d.vmax - 1) / (8 * d.vmax)
+	if n == 1 {
+		c := comps[0]
+		mx = ((d.width*int(c.h)+d.hmax-1)/d.hmax + 7) / 8
+		my = ((d.height*int(c.v)+d.vmax-1)/d.vmax + 7) / 8
+		comps[0].jpegComponent = &jpegComponent{pix: c.pix, stride: c.stride, tq: c.tq, h: 1, v: 1, bw: c.bw, bh: c.bh}
+	}
+
+	br := jpegBits{data: d.data, pos: p}
+	var block [64]int32
+	for m := 0; m < mx*my; m++ {
+		if d.restart > 0 && m > 0 && m%d.restart == 0 {
+			br.reset()
+			for i := range comps {
+				comps[i].pred = 0
+			}
+		}
+		bx, by := m%mx, m/mx
+		for i := range comps {
+			c := &comps[i]
+			for v := 0; v < int(c.v); v++ {
+				for h := 0; h < int(c.h); h++ {
+					if err := d.block(&br, &block, c.dc, c.ac, &c.pred, c.tq); err != nil {
+						return p, err
+					}
+					if br.short {
+						return p, errJPEGUnsupported
+					}
+					x := (bx*int(c.h) + h) * c.bw
+					y := (by*int(c.v) + v) * c.bh
+					d.inverse(&block, c.pix[y*c.stride+x:], c.stride, c.bw, c.bh)
+				}
+			}
+		}
+	}
+	return br.pos, nil
+}
+
+// block decodes and dequantizes the coefficients of a block.
+func (d *jpegDecoder) block(br *jpegBits, block *[64]int32, dc, ac *jpegHuffman, pred *int32, tq uint8) error {
+	*block = [64]int32{}
+	q := &d.quant[tq]
+
+	t, err := br.decode(dc)
+	if err != nil {
+		return err
+	}
+	if t > 11 {
+		return errJPEGUnsupported
+	}
+	*pred += br.receive(t)
+	block[0] = *pred * q[0]
+
+	for k := 1; k < 64; k++ {
+		rs, err := br.decode(ac)
+		if err != nil {
+			return err
+		}
+		r, s := rs>>4, rs&15
+		if s == 0 {
+			if r != 15 {
+				break
+			}
+			k += 15
+			continue
+		}
+		k += int(r)
+		if k > 63 {
+			return errJPEGUnsupported
+		}
+		block[jpegUnzig[k]] = br.receive(s) * q[jpegUnzig[k]]
+	}
+	return nil
+}
+
+// inverse computes the nx×ny inverse DCT of the lower frequencies of a block.
+func (d *jpegDecoder) inverse(block *[64]int32, dst []uint8, stride, nx, ny int) {
+	cx, cy := d.cosines(nx), d.cosines(ny)
+	var tmp [8][8]float64 // [v][x]
+	for v := 0; v < ny; v++ {
+		for x := 0; x < nx; x++ {
+			var s float64
+			for u := 0; u < nx; u++ {
+				s += cx[x][u] * float64(block[8*v+u])
+			}
+			tmp[v][x] = s
+		}
+	}
+	for y := 0; y < ny; y++ {
+		for x := 0; x < nx; x++ {
+			s := 128.5
+			for v := 0; v < ny; v++ {
+				s += cy[y][v] * tmp[v][x]
+			}
+			dst[y*stride+x] = uint8(max(0, min(math.Floor(s), 255)))
+		}
+	}
+}
+
+// cosines returns the (scaled) basis of the n-point inverse DCT.
+func (d *jpegDecoder) cosines(n int) *[8][8]float64 {
+	if t := d.idct[n]; t != nil {
+		return t
+	}
+	t := new([8][8]float64)
+	for x := 0; x < n; x++ {
+		for u := 0; u < n; u++ {
+			c := 0.5
+			if u == 0 {
+				c = 0.5 / math.Sqrt2
+			}
+			t[x][u] = c * math.Cos(float64((2*x+1)*u)*math.Pi/float64(2*n))
+		}
+	}
+	d.idct[n] = t
+	return t
+}
+
+// jpegBits reads the bits of entropy coded data, removing stuffed bytes.
+// At a marker, it reads zero bits, without consuming the marker.
+// At the end of the data, it also reads zero bits, but flags the data as short.
+type jpegBits struct {
+	data  []byte
+	pos   int
+	acc   uint32 // the low n bits are unread
+	n     int
+	short bool
+}
+
+// fill reads bytes into the accumulator, until it holds more than 24 bits.
+func (b *jpegBits) fill() {
+	for b.n <= 24 {
+		var c byte
+		if b.pos < len(b.data) {
+			c = b.data[b.pos]
+			if c != 0xff {
+				b.pos++
+			} else if b.pos+1 < len(b.data) && b.data[b.pos+1] == 0 {
+				b.pos += 2
+			} else {
+				c = 0
+				b.short = b.short || b.pos+1 >= len(b.data)
+			}
+		} else {
+			b.short = true
+		}
+		b.acc = b.acc<<8 | uint32(c)
+		b.n += 8
+	}
+}
+
+func (b *jpegBits) bit() int32 {
+	if b.n == 0 {
+		b.fill()
+	}
+	b.n--
+	return int32(b.acc>>b.n) & 1
+}
+
+// receive reads s bits, and extends their sign, as in F.2.2.1 of the spec.
+func (b *jpegBits) receive(s uint8) int32 {
+	if s == 0 {
+		return 0
+	}
+	if b.n < int(s) {
+		b.fill()
+	}
+	b.n -= int(s)
+	v := int32(b.acc>>b.n) & (1<<s - 1)
+	if v < 1<<(s-1) {
+		v += -1<<s + 1
+	}
+	return v
+}
+
+func (b *jpegBits) decode(h *jpegHuffman) (uint8, error) {
+	if b.n < jpegLookahead {
+		b.fill()
+	}
+	if e := h.lookup[b.acc>>(b.n-jpegLookahead)&(1<<jpegLookahead-1)]; e != 0 {
+		b.n -= int(e & 0xff)
+		return uint8(e >> 8), nil
+	}
+
+	// longer codes, bit by bit
+	code := b.bit()
+	l := 1
+	for code > h.maxcode[l] {
+		if l == 16 {
+			return 0, errJPEGUnsupported
+		}
+		code = code<<1 | b.bit()
+		l++
+	}
+	i := h.valptr[l] + code - h.mincode[l]
+	if int(i) >= len(h.value) {
+		return 0, errJPEGUnsupported
+	}
+	return h.value[i], nil
+}
+
+// reset discards remaining bits, and skips the next RST marker.
+func (b *jpegBits) reset() {
+	b.n = 0
+	for b.pos+1 < len(b.data) {
+		if b.data[b.pos] == 0xff && 0xd0 <= b.data[b.pos+1] && b.data[b.pos+1] <= 0xd7 {
+			b.pos += 2
+			return
+		}
+		b.pos++
+	}
+}
@@ -0,0 +1,196 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"reflect"
+	"testing"
+
+	"github.com/ncruces/go-image/imageutil"
+)
+
+// testJPEG encodes a smooth test pattern as JPEG.
+func testJPEG(t *testing.T, w, h int, gray bool) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{uint8(255 * x / w), uint8(255 * y / h), 128, 255}
+			if (x-w/2)*(x-w/2)+(y-h/2)*(y-h/2) < h*h/9 {
+				c = color.RGBA{240, 200, 30, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	var src image.Image = img
+	if gray {
+		g := image.NewGray(img.Rect)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				g.Set(x, y, img.At(x, y))
+			}
+		}
+		src = g
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// meanDiff computes the mean absolute difference between the color channels of two same-size images.
+func meanDiff(a, b image.Image) float64 {
+	ba, bb := a.Bounds(), b.Bounds()
+	var sum, n float64
+	for y := 0; y < ba.Dy(); y++ {
+		for x := 0; x < ba.Dx(); x++ {
+			c1 := color.NRGBAModel.Convert(a.At(ba.Min.X+x, ba.Min.Y+y)).(color.NRGBA)
+			c2 := color.NRGBAModel.Convert(b.At(bb.Min.X+x, bb.Min.Y+y)).(color.NRGBA)
+			for _, d := range []int{int(c1.R) - int(c2.R), int(c1.G) - int(c2.G), int(c1.B) - int(c2.B)} {
+				sum += float64(max(d, -d))
+				n++
+			}
+		}
+	}
+	return sum / n
+}
+
+func Test_DecodeJPEGScaled(t *testing.T) {
+	for _, gray := range []bool{false, true} {
+		data := testJPEG(t, 256, 192, gray)
+		full, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// exact fractions are decoded with the DCT alone
+		for _, max := range []int{1000, 256, 128, 64, 32} {
+			img, err := DecodeJPEGScaled(bytes.NewReader(data), max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, h := min(max, 256), min(max, 256)*3/4
+			if img.Bounds() != image.Rect(0, 0, w, h) {
+				t.Fatalf("gray %v, max %d: unexpected bounds: %v", gray, max, img.Bounds())
+			}
+			if _, ok := img.(*image.NRGBA); ok {
+				t.Errorf("gray %v, max %d: unexpected resize", gray, max)
+			}
+
+			// full size is decoded by image/jpeg
+			if w == 256 && !reflect.DeepEqual(img, full) {
+				t.Errorf("gray %v, max %d: expected the image/jpeg decode", gray, max)
+			}
+
+			// DCT scaling averages gamma encoded values
+			ref := full
+			if w < 256 {
+				ref = ResizeWith(full, w, h, ResizeOptions{Filter: FilterArea, Gamma: true})
+			}
+			if d := meanDiff(img, ref); d > 2 {
+				t.Errorf("gray %v, max %d: mean difference %v", gray, max, d)
+			}
+		}
+
+		// other sizes are finished with an area downscale
+		for _, max := range []int{200, 100, 40, 10} {
+			img, err := DecodeJPEGScaled(bytes.NewReader(data), max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := int(0.5 + float64(max*3)/4)
+			if img.Bounds() != image.Rect(0, 0, max, h) {
+				t.Fatalf("gray %v, max %d: unexpected bounds: %v", gray, max, img.Bounds())
+			}
+			ref := ResizeWith(full, max, h, ResizeOptions{Filter: FilterArea})
+			if d := meanDiff(img, ref); d > 2 {
+				t.Errorf("gray %v, max %d: mean difference %v", gray, max, d)
+			}
+		}
+	}
+}
+
+func Test_DecodeJPEGScaledBounds(t *testing.T) {
+	// partial blocks round up
+	data := testJPEG(t, 250, 190, false)
+	for _, tt := range []struct {
+		max  int
+		size image.Point
+	}{
+		{250, image.Pt(250, 190)},
+		{125, image.Pt(125, 95)},
+		{63, image.Pt(63, 48)},
+		{32, image.Pt(32, 24)},
+		{31, image.Pt(31, 24)},
+	} {
+		img, err := DecodeJPEGScaled(bytes.NewReader(data), tt.max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds() != (image.Rectangle{Max: tt.size}) {
+			t.Errorf("max %d: unexpected bounds: %v", tt.max, img.Bounds())
+		}
+	}
+}
+
+func Test_DecodeJPEGScaledInvalid(t *testing.T) {
+	data := testJPEG(t, 64, 64, false)
+	if _, err := DecodeJPEGScaled(bytes.NewReader(data[:100]), 8); err == nil {
+		t.Error("expected an error for a truncated image")
+	}
+	// truncated in the entropy coded data, or just the EOI
+	for _, n := range []int{len(data) * 7 / 10, len(data) - 2} {
+		if _, err := DecodeJPEGScaled(bytes.NewReader(data[:n]), 8); err == nil {
+			t.Errorf("expected an error for an image truncated at %d of %d bytes", n, len(data))
+		}
+	}
+	if _, err := DecodeJPEGScaled(bytes.NewReader([]byte("not a JPEG")), 8); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func Test_DecodeJPEGScaledSubsampling(t *testing.T) {
+	full, err := jpeg.Decode(bytes.NewReader(testJPEG(t, 256, 192, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ratio := range []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	} {
+		var buf bytes.Buffer
+		if err := imageutil.EncodeJPEG(&buf, full, 95, ratio); err != nil {
+			t.Fatal(err)
+		}
+		ref, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, max := range []int{256, 128, 32} {
+			img, err := DecodeJPEGScaled(bytes.NewReader(buf.Bytes()), max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := img.(*image.YCbCr); !ok {
+				t.Fatalf("%v, max %d: expected *image.YCbCr, got %T", ratio, max, img)
+			}
+			exp := ref
+			if max < 256 {
+				exp = ResizeWith(ref, max, max*3/4, ResizeOptions{Filter: FilterArea, Gamma: true})
+			}
+			if d := meanDiff(img, exp); d > 2 {
+				t.Errorf("%v, max %d: mean difference %v", ratio, max, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package resize
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/ncruces/go-image/imageutil"
+	"github.com/ncruces/go-image/internal/tiles"
+)
+
+// Filter selects the resampling filter used by ResizeWith.
+type Filter int
+
+// Filter constants
+const (
+	// Nearest-neighbor sampling
+	FilterNearest Filter = iota
+	// Bilinear (triangle) filter
+	FilterBilinear
+	// Area averaging (box) filter
+	FilterArea
+	// Lanczos filter (a=3)
+	FilterLanczos
+)
+
+// ResizeOptions control how ResizeWith resamples an image.
+//
+// The zero value resizes with nearest-neighbor sampling, in linear light, with premultiplied alpha.
+type ResizeOptions struct {
+	// Filter is the resampling filter.
+	// Except for FilterNearest, filters widen with the reduction factor when downscaling, which avoids aliasing.
+	Filter Filter
+	// Gamma resamples gamma encoded sRGB values, instead of linear light.
+	// This is faster to compute, but darkens fine, high contrast detail.
+	Gamma bool
+	// Straight resamples color and alpha independently, instead of premultiplying colors by alpha.
+	// The colors of transparent pixels then bleed into their neighbors.
+	Straight bool
+}
+
+// ResizeWith scales an image to new width and height, as specified by opts.
+// If one of the parameters w or h is set to 0, its size will be calculated so that
+// the aspect ratio is that of the originating image.
+//
+// The filter is separable, and clamps to the edges of the image.
+// The result is anchored at the origin.
+func ResizeWith(src image.Image, w, h int, opts ResizeOptions) *image.NRGBA {
+	if opts.Filter < FilterNearest || opts.Filter > FilterLanczos {
+		panic("Unknown filter")
+	}
+
+	bounds := src.Bounds()
+	w, h = resizeSize(bounds, w, h)
+	if bounds.Empty() {
+		return image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+	sw, sh := bounds.Dx(), bounds.Dy()
+
+	in, ok := src.(*image.NRGBA)
+	if !ok || in.Rect.Min != (image.Point{}) {
+		in = image.NewNRGBA(image.Rect(0, 0, sw, sh))
+		draw.Draw(in, in.Rect, src, bounds.Min, draw.Src)
+	}
+
+	pix := make([]float32, 4*sw*sh)
+	for y := 0; y < sh; y++ {
+		s := in.Pix[y*in.Stride:]
+		p := pix[4*sw*y:]
+		for x := 0; x < sw; x++ {
+			a := float32(s[3]) / 255
+			for c := 0; c < 3; c++ {
+				v := float32(s[c]) / 255
+				if !opts.Gamma {
+					v = float32(imageutil.SRGB8ToLinear(s[c])) / 65535
+				}
+				if !opts.Straight {
+					v *= a
+				}
+				p[c] = v
+			}
+			p[3] = a
+			s = s[4:]
+			p = p[4:]
+		}
+	}
+
+	xo, xw := filterWeights(w, sw, opts.Filter)
+	yo, yw := filterWeights(h, sh, opts.Filter)
+	pix = resample(pix, sw, sh, w, h, xo, xw, yo, yw)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(pix); i += 4 {
+		p := pix[i : i+4 : i+4]
+		a := max(0, min(p[3], 1))
+		if a == 0 && !opts.Straight {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			v := p[c]
+			if !opts.Straight {
+				v /= p[3]
+			}
+			v = max(0, min(v, 1))
+			if opts.Gamma {
+				dst.Pix[i+c] = uint8(math.Floor(255*float64(v) + 0.5))
+			} else {
+				dst.Pix[i+c] = imageutil.LinearToSRGB8(uint16(math.Floor(65535*float64(v) + 0.5)))
+			}
+		}
+		dst.Pix[i+3] = uint8(math.Floor(255*float64(a) + 0.5))
+	}
+	return dst
+}
+
+// resizeSize computes the size of a resized image, keeping the aspect ratio if one of w or h is 0.
+func resizeSize(bounds image.Rectangle, w, h int) (int, int) {
+	if w <= 0 && h <= 0 {
+		return bounds.Dx(), bounds.Dy()
+	}
+	if w <= 0 {
+		w = int(0.7 + float64(bounds.Dx()*h)/float64(bounds.Dy()))
+	}
+	if h <= 0 {
+		h = int(0.7 + float64(bounds.Dy()*w)/float64(bounds.Dx()))
+	}
+	return w, h
+}
+
+// resample applies a separable filter to sw×sh pixels of 4 float32 channels, resulting in w×h pixels.
+// The offsets and weights of each pass are computed by filterWeights, or gaussianWeights.
+// Each pass is split into tiles, that run in parallel.
+func resample(pix []float32, sw, sh, w, h int, xoffset [][]int, xweights [][]float32, yoffset [][]int, yweights [][]float32) []float32 {
+	// horizontal pass
+	tmp := make([]float32, 4*w*sh)
+	tiles.Map(image.Rect(0, 0, w, sh), 64, 0, func(t image.Rectangle) {
+		for y := t.Min.Y; y < t.Max.Y; y++ {
+			row := pix[4*sw*y:]
+			for x := t.Min.X; x < t.Max.X; x++ {
+				var r, g, b, a float32
+				for i, wt := range xweights[x] {
+					p := row[4*xoffset[x][i]:]
+					r += wt * p[0]
+					g += wt * p[1]
+					b += wt * p[2]
+					a += wt * p[3]
+				}
+				p := tmp[4*(w*y+x):]
+				p[0], p[1], p[2], p[3] = r, g, b, a
+			}
+		}
+	})
+
+	// vertical pass
+	dst := make([]float32, 4*w*h)
+	tiles.Map(image.Rect(0, 0, w, h), 64, 0, func(t image.Rectangle) {
+		for y := t.Min.Y; y < t.Max.Y; y++ {
+			for x := t.Min.X; x < t.Max.X; x++ {
+				var r, g, b, a float32
+				for i, wt := range yweights[y] {
+					p := tmp[4*(w*yoffset[y][i]+x):]
+					r += wt * p[0]
+					g += wt * p[1]
+					b += wt * p[2]
+					a += wt * p[3]
+				}
+				p := dst[4*(w*y+x):]
+				p[0], p[1], p[2], p[3] = r, g, b, a
+			}
+		}
+	})
+	return dst
+}
+
+// filterWeights computes, for each of the dst samples, the src samples (clamped) and weights to use.
+func filterWeights(dst, src int, f Filter) ([][]int, [][]float32) {
+	scale := float64(src) / float64(dst)
+
+	offset := make([][]int, dst)
+	weights := make([][]float32, dst)
+	for i := range offset {
+		var start int
+		var ws []float64
+
+		switch f {
+		case FilterNearest:
+			start = min(int((float64(i)+0.5)*scale), src-1)
+			ws = []float64{1}
+
+		case FilterArea:
+			// the overlap of the dst pixel with each src pixel
+			lo, hi := float64(i)*scale, float64(i+1)*scale
+			start = int(math.Floor(lo))
+			for j := start; float64(j) < hi; j++ {
+				ws = append(ws, math.Min(hi, float64(j+1))-math.Max(lo, float64(j)))
+			}
+
+		default:
+			support, kernel := 1.0, linear
+			if f == FilterLanczos {
+				support, kernel = 3, lanczos3
+			}
+			fscale := math.Max(scale, 1)
+			center := (float64(i)+0.5)*scale - 0.5
+			start = int(math.Ceil(center - support*fscale))
+			end := int(math.Floor(center + support*fscale))
+			for j := start; j <= end; j++ {
+				ws = append(ws, kernel((float64(j)-center)/fscale))
+			}
+		}
+
+		var sum float64
+		for _, w := range ws {
+			sum += w
+		}
+
+		offset[i] = make([]int, len(ws))
+		weights[i] = make([]float32, len(ws))
+		for k, w := range ws {
+			offset[i][k] = max(0, min(start+k, src-1))
+			weights[i][k] = float32(w / sum)
+		}
+	}
+	return offset, weights
+}
@@ -0,0 +1,105 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeWithBounds(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 10, 210, 110))
+
+	for f := FilterNearest; f <= FilterLanczos; f++ {
+		if m := ResizeWith(src, 50, 0, ResizeOptions{Filter: f}); m.Bounds() != image.Rect(0, 0, 50, 25) {
+			t.Errorf("%d: unexpected bounds: %v", f, m.Bounds())
+		}
+		if m := ResizeWith(src, 0, 150, ResizeOptions{Filter: f}); m.Bounds() != image.Rect(0, 0, 300, 150) {
+			t.Errorf("%d: unexpected bounds: %v", f, m.Bounds())
+		}
+	}
+}
+
+func Test_ResizeWithSameColor(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{200, 100, 50, 128})
+		}
+	}
+
+	for _, opts := range []ResizeOptions{
+		{Filter: FilterNearest},
+		{Filter: FilterBilinear},
+		{Filter: FilterArea, Gamma: true},
+		{Filter: FilterLanczos, Straight: true},
+	} {
+		for _, size := range []image.Point{{7, 5}, {45, 31}} {
+			m := ResizeWith(src, size.X, size.Y, opts)
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					if c := m.NRGBAAt(x, y); c != (color.NRGBA{200, 100, 50, 128}) {
+						t.Fatalf("%+v, at %dx%d: unexpected color %v", opts, x, y, c)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeWithCheckerboard(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if (x+y)%2 == 0 {
+				src.Pix[y*src.Stride+x] = 255
+			}
+		}
+	}
+
+	// half the light of white is 50% linear, which encodes to 188 in sRGB;
+	// averaging gamma encoded values gets 128, which is much darker
+	tests := []struct {
+		gamma bool
+		want  uint8
+	}{
+		{false, 188},
+		{true, 128},
+	}
+	for _, tt := range tests {
+		m := ResizeWith(src, 4, 4, ResizeOptions{Filter: FilterArea, Gamma: tt.gamma})
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				if c := m.NRGBAAt(x, y); c != (color.NRGBA{tt.want, tt.want, tt.want, 255}) {
+					t.Fatalf("gamma %v, at %dx%d: unexpected color %v", tt.gamma, x, y, c)
+				}
+			}
+		}
+	}
+
+	// other filters weigh pixels unevenly at the edges, but are always brighter in linear light
+	for _, f := range []Filter{FilterBilinear, FilterLanczos} {
+		lin := ResizeWith(src, 4, 4, ResizeOptions{Filter: f})
+		gam := ResizeWith(src, 4, 4, ResizeOptions{Filter: f, Gamma: true})
+		for i := 0; i < len(lin.Pix); i += 4 {
+			if lin.Pix[i] <= gam.Pix[i]+32 {
+				t.Fatalf("filter %d: linear %d, gamma %d", f, lin.Pix[i], gam.Pix[i])
+			}
+		}
+	}
+}
+
+func Test_ResizeWithPremultiplied(t *testing.T) {
+	// opaque red, next to transparent green
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{255, 0, 0, 255})
+	src.SetNRGBA(1, 0, color.NRGBA{0, 255, 0, 0})
+
+	m := ResizeWith(src, 1, 1, ResizeOptions{Filter: FilterArea, Gamma: true})
+	if c := m.NRGBAAt(0, 0); c != (color.NRGBA{255, 0, 0, 128}) {
+		t.Errorf("premultiplied: unexpected color %v", c)
+	}
+	m = ResizeWith(src, 1, 1, ResizeOptions{Filter: FilterArea, Gamma: true, Straight: true})
+	if c := m.NRGBAAt(0, 0); c != (color.NRGBA{128, 128, 0, 128}) {
+		t.Errorf("straight: unexpected color %v", c)
+	}
+}
@@ -0,0 +1,135 @@
+package resize
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/ncruces/go-image/rotateflip"
+)
+
+// SeamCarve shrinks an image by dw columns and dh rows, using content-aware seam carving.
+//
+// Seams are removed one at a time, following the path of least energy,
+// as measured by the Sobel gradient magnitude of the image's luma.
+// Vertical seams (columns) are removed before horizontal seams (rows).
+// Only shrinking is supported: dw and dh must not be negative,
+// and must be smaller than the width and height of the image.
+func SeamCarve(src image.Image, dw, dh int) (*image.NRGBA, error) {
+	bounds := src.Bounds()
+	if dw < 0 || dh < 0 {
+		return nil, errors.New("resize: seam insertion is not supported")
+	}
+	if dw >= bounds.Dx() || dh >= bounds.Dy() {
+		return nil, errors.New("resize: too many seams to remove")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Rect, src, bounds.Min, draw.Src)
+
+	for i := 0; i < dw; i++ {
+		img = removeSeam(img)
+	}
+	if dh > 0 {
+		img = rotateflip.Image(img, rotateflip.Transpose).(*image.NRGBA)
+		for i := 0; i < dh; i++ {
+			img = removeSeam(img)
+		}
+		img = rotateflip.Image(img, rotateflip.Transpose).(*image.NRGBA)
+	}
+	return img, nil
+}
+
+// removeSeam removes the vertical seam of least energy from an image.
+func removeSeam(img *image.NRGBA) *image.NRGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	cost := seamEnergy(img)
+
+	// cumulative minimum energy, top to bottom
+	for y := 1; y < h; y++ {
+		prev := cost[(y-1)*w : y*w]
+		row := cost[y*w : (y+1)*w]
+		for x := range row {
+			m := prev[x]
+			if x > 0 && prev[x-1] < m {
+				m = prev[x-1]
+			}
+			if x < w-1 && prev[x+1] < m {
+				m = prev[x+1]
+			}
+			row[x] += m
+		}
+	}
+
+	// backtrack, bottom to top
+	seam := make([]int, h)
+	last := cost[(h-1)*w:]
+	for x := range last {
+		if last[x] < last[seam[h-1]] {
+			seam[h-1] = x
+		}
+	}
+	for y := h - 2; y >= 0; y-- {
+		row := cost[y*w : (y+1)*w]
+		x := seam[y+1]
+		best := x
+		if x > 0 && row[x-1] < row[best] {
+			best = x - 1
+		}
+		if x < w-1 && row[x+1] < row[best] {
+			best = x + 1
+		}
+		seam[y] = best
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w-1, h))
+	for y, x := range seam {
+		s := img.Pix[y*img.Stride : y*img.Stride+4*w]
+		d := dst.Pix[y*dst.Stride : y*dst.Stride+4*(w-1)]
+		copy(d, s[:4*x])
+		copy(d[4*x:], s[4*x+4:])
+	}
+	return dst
+}
+
+// seamEnergy computes the Sobel gradient magnitude of an image's luma, clamping at the edges.
+func seamEnergy(img *image.NRGBA) []float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+
+	luma := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := img.Pix[y*img.Stride+4*x:]
+			luma[y*w+x] = 0.299*float64(p[0]) + 0.587*float64(p[1]) + 0.114*float64(p[2])
+		}
+	}
+
+	at := func(x, y int) float64 {
+		if x < 0 {
+			x = 0
+		}
+		if x >= w {
+			x = w - 1
+		}
+		if y < 0 {
+			y = 0
+		}
+		if y >= h {
+			y = h - 1
+		}
+		return luma[y*w+x]
+	}
+
+	energy := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) -
+				at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			energy[y*w+x] = math.Hypot(gx, gy)
+		}
+	}
+	return energy
+}
@@ -0,0 +1,66 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_SeamCarve(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 10, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 10; x++ {
+			if x == 6 {
+				src.SetGray(x, y, color.Gray{255})
+			} else {
+				src.SetGray(x, y, color.Gray{100})
+			}
+		}
+	}
+
+	for n := 1; n <= 5; n++ {
+		m, err := SeamCarve(src, n, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Rect != image.Rect(0, 0, 10-n, 6) {
+			t.Fatalf("unexpected bounds: %v", m.Rect)
+		}
+
+		var cols int
+		for x := 0; x < m.Rect.Dx(); x++ {
+			bright := true
+			for y := 0; y < 6; y++ {
+				if m.NRGBAAt(x, y).R != 255 {
+					bright = false
+				}
+			}
+			if bright {
+				cols++
+			}
+		}
+		if cols != 1 {
+			t.Errorf("%d seams: high energy column not preserved", n)
+		}
+	}
+
+	m, err := SeamCarve(src, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Rect != image.Rect(0, 0, 10, 4) {
+		t.Fatalf("unexpected bounds: %v", m.Rect)
+	}
+	for y := 0; y < 4; y++ {
+		if m.NRGBAAt(6, y).R != 255 {
+			t.Errorf("at 6x%d: high energy column not preserved", y)
+		}
+	}
+
+	if _, err := SeamCarve(src, -1, 0); err == nil {
+		t.Error("expected error for insertion")
+	}
+	if _, err := SeamCarve(src, 10, 0); err == nil {
+		t.Error("expected error for removing every column")
+	}
+}
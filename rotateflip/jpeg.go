@@ -0,0 +1,181 @@
+package rotateflip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// AutoOrientBytes normalizes the orientation of a JPEG image.
+//
+// If the EXIF Orientation of the image is other than TopLeft,
+// the image is decoded, rotated and flipped to TopLeft,
+// and re-encoded with options o (as in jpeg.Encode, nil for the default quality) with its Orientation reset.
+// Other metadata (JFIF, the rest of EXIF, XMP and ICC profiles) is preserved;
+// if the image is transposed, so is the JFIF pixel density.
+// Other application segments (e.g. APP14 Adobe) don't apply to the re-encoded image, and are dropped.
+//
+// The flag reports whether the image was changed; if not, data is returned as is.
+func AutoOrientBytes(data []byte, o *jpeg.Options) ([]byte, bool, error) {
+	or, _, _ := jpegOrientation(data)
+	if or == TopLeft {
+		return data, false, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, Image(img, or.Op()), o)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// copy application segments after SOI, and reset the orientation
+	segments := jpegAppSegments(data)
+	res := make([]byte, 0, buf.Len()+len(segments))
+	res = append(res, buf.Bytes()[:2]...)
+	res = append(res, segments...)
+	res = append(res, buf.Bytes()[2:]...)
+
+	if _, offset, order := jpegOrientation(res); offset > 0 {
+		order.PutUint16(res[offset:], uint16(TopLeft))
+	}
+	if or.Op().Rotates() {
+		// swap the horizontal and vertical JFIF densities
+		jpegSegments(res, func(marker byte, start, end int) bool {
+			if marker == 0xe0 && end-start >= 14 && string(res[start:start+5]) == "JFIF\x00" {
+				d := res[start+8 : start+12]
+				d[0], d[1], d[2], d[3] = d[2], d[3], d[0], d[1]
+			}
+			return true
+		})
+	}
+	return res, true, nil
+}
+
+// DecodeJPEGOriented decodes a JPEG image, and applies its EXIF Orientation.
+//
+// Only the header of the image is buffered to find its Orientation.
+// Decoded YCbCr images are rotated plane by plane, and returned as YCbCr,
+// so no intermediate RGBA image is allocated.
+func DecodeJPEGOriented(r io.Reader) (image.Image, error) {
+	// the EXIF segment is at most 64KiB, and should follow SOI, and maybe JFIF
+	br := bufio.NewReaderSize(r, 1<<17)
+	header, err := br.Peek(br.Size())
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	or, _, _ := jpegOrientation(header)
+
+	img, err := jpeg.Decode(br)
+	if err != nil {
+		return nil, err
+	}
+	return Image(img, or.Op()), nil
+}
+
+// jpegOrientation finds the EXIF Orientation of a JPEG image,
+// the offset of its value in data, and the byte order of the EXIF data.
+// It returns TopLeft if the image has no valid Orientation.
+func jpegOrientation(data []byte) (or Orientation, offset int, order binary.ByteOrder) {
+	or = TopLeft
+	jpegSegments(data, func(marker byte, start, end int) bool {
+		seg := data[start:end]
+		if marker != 0xe1 || !bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return true
+		}
+
+		tiff := seg[6:]
+		if len(tiff) < 8 {
+			return false
+		}
+		switch string(tiff[:4]) {
+		case "II*\x00":
+			order = binary.LittleEndian
+		case "MM\x00*":
+			order = binary.BigEndian
+		default:
+			return false
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd < 8 || ifd+2 > len(tiff) {
+			return false
+		}
+		count := int(order.Uint16(tiff[ifd:]))
+		for i := 0; i < count; i++ {
+			entry := ifd + 2 + 12*i
+			if entry+12 > len(tiff) {
+				break
+			}
+			// Orientation: tag 0x0112, type SHORT, count 1
+			if order.Uint16(tiff[entry:]) == 0x0112 &&
+				order.Uint16(tiff[entry+2:]) == 3 &&
+				order.Uint32(tiff[entry+4:]) == 1 {
+				if v := Orientation(order.Uint16(tiff[entry+8:])); TopLeft <= v && v <= LeftBottom {
+					or, offset = v, start+6+entry+8
+				}
+				break
+			}
+		}
+		return false
+	})
+	return or, offset, order
+}
+
+// jpegSegments calls fn with the marker and payload bounds of each segment
+// preceding the image data of a JPEG image, until fn returns false.
+func jpegSegments(data []byte, fn func(marker byte, start, end int) bool) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xff {
+		marker := data[pos+1]
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+		n := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + n
+		if n < 2 || end > len(data) {
+			break
+		}
+		if !fn(marker, pos+4, end) {
+			break
+		}
+		pos = end
+	}
+}
+
+// jpegAppSegments returns the metadata segments of a JPEG image that remain valid
+// for a re-encoded image, with markers: APP0 JFIF, APP1 EXIF and XMP, and APP2 ICC profiles.
+// Other segments (e.g. APP14 Adobe, which describes a color transform) are dropped.
+func jpegAppSegments(data []byte) []byte {
+	var res []byte
+	jpegSegments(data, func(marker byte, start, end int) bool {
+		seg := string(data[start:end])
+		var keep bool
+		switch marker {
+		case 0xe0:
+			keep = strings.HasPrefix(seg, "JFIF\x00")
+		case 0xe1:
+			keep = strings.HasPrefix(seg, "Exif\x00") ||
+				strings.HasPrefix(seg, "http://ns.adobe.com/xap/1.0/\x00") ||
+				strings.HasPrefix(seg, "http://ns.adobe.com/xmp/extension/\x00")
+		case 0xe2:
+			keep = strings.HasPrefix(seg, "ICC_PROFILE\x00")
+		}
+		if keep {
+			res = append(res, data[start-4:end]...)
+		}
+		return true
+	})
+	return res
+}
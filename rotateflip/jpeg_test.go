@@ -0,0 +1,239 @@
+package rotateflip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// exifJPEG encodes an image as JPEG, with an EXIF segment holding an Orientation.
+func exifJPEG(t *testing.T, img image.Image, or Orientation, order binary.ByteOrder) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// TIFF header, and an IFD with Orientation and Software entries
+	tiff := make([]byte, 8+2+2*12+4, 64)
+	if order == binary.LittleEndian {
+		copy(tiff, "II*\x00")
+	} else {
+		copy(tiff, "MM\x00*")
+	}
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 2)
+	entry := tiff[10:]
+	order.PutUint16(entry[0:], 0x0112)
+	order.PutUint16(entry[2:], 3)
+	order.PutUint32(entry[4:], 1)
+	order.PutUint16(entry[8:], uint16(or))
+	entry = tiff[22:]
+	order.PutUint16(entry[0:], 0x0131)
+	order.PutUint16(entry[2:], 2)
+	order.PutUint32(entry[4:], 8)
+	order.PutUint32(entry[8:], uint32(len(tiff)))
+	tiff = append(tiff, "go-image"...)
+
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(seg)+2))
+
+	res := append([]byte{}, buf.Bytes()[:2]...)
+	res = append(res, app1...)
+	res = append(res, seg...)
+	return append(res, buf.Bytes()[2:]...)
+}
+
+func Test_AutoOrientBytes(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			img.Pix[y*img.Stride+x] = uint8(8 * x)
+		}
+	}
+
+	t.Run("TopLeft", func(t *testing.T) {
+		data := exifJPEG(t, img, TopLeft, binary.BigEndian)
+		res, changed, err := AutoOrientBytes(data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed || !bytes.Equal(res, data) {
+			t.Error("expected the image to be unchanged")
+		}
+	})
+
+	t.Run("NoEXIF", func(t *testing.T) {
+		var buf bytes.Buffer
+		jpeg.Encode(&buf, img, nil)
+		res, changed, err := AutoOrientBytes(buf.Bytes(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed || !bytes.Equal(res, buf.Bytes()) {
+			t.Error("expected the image to be unchanged")
+		}
+	})
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			data := exifJPEG(t, img, RightTop, order)
+			res, changed, err := AutoOrientBytes(data, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed {
+				t.Fatal("expected the image to change")
+			}
+
+			if or, _, _ := jpegOrientation(res); or != TopLeft {
+				t.Errorf("expected TopLeft, got %d", or)
+			}
+			if !bytes.Contains(res, []byte("go-image")) {
+				t.Error("EXIF data was lost")
+			}
+
+			dec, err := jpeg.Decode(bytes.NewReader(res))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := image.Rect(0, 0, 16, 32); dec.Bounds() != r {
+				t.Fatalf("expected: %v, got: %v", r, dec.Bounds())
+			}
+			// rotated clockwise: the gradient runs top to bottom
+			top, _, _, _ := dec.At(8, 2).RGBA()
+			bottom, _, _, _ := dec.At(8, 29).RGBA()
+			if top >= bottom {
+				t.Errorf("expected a vertical gradient, got %#x, %#x", top, bottom)
+			}
+		})
+	}
+
+	t.Run("Quality", func(t *testing.T) {
+		data := exifJPEG(t, img, RightTop, binary.BigEndian)
+		lo, _, err := AutoOrientBytes(data, &jpeg.Options{Quality: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hi, _, err := AutoOrientBytes(data, &jpeg.Options{Quality: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lo) >= len(hi) {
+			t.Errorf("expected a lower quality to be smaller: %d, %d bytes", len(lo), len(hi))
+		}
+	})
+
+	t.Run("Segments", func(t *testing.T) {
+		segment := func(marker byte, payload string) []byte {
+			return append([]byte{0xff, marker, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+		}
+		icc := segment(0xe2, "ICC_PROFILE\x00\x01\x01profile")
+		xmp := segment(0xe1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")
+		adobe := segment(0xee, "Adobe\x00\x64\x00\x00\x00\x00\x01")
+		other := segment(0xe5, "private")
+
+		data := exifJPEG(t, img, RightTop, binary.BigEndian)
+		var segs []byte
+		for _, s := range [][]byte{icc, xmp, adobe, other} {
+			segs = append(segs, s...)
+		}
+		data = append(data[:2:2], append(segs, data[2:]...)...)
+
+		res, _, err := AutoOrientBytes(data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(res, icc) || !bytes.Contains(res, xmp) || !bytes.Contains(res, []byte("go-image")) {
+			t.Error("metadata was lost")
+		}
+		if bytes.Contains(res, adobe) || bytes.Contains(res, other) {
+			t.Error("expected APP14 Adobe and unknown segments to be dropped")
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(res)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("JFIF", func(t *testing.T) {
+		// a JFIF segment, with 72×144 dpi, before EXIF
+		jfif := []byte("\xff\xe0\x00\x10JFIF\x00\x01\x02\x01\x00\x48\x00\x90\x00\x00")
+		data := exifJPEG(t, img, RightTop, binary.BigEndian)
+		data = append(data[:2:2], append(jfif, data[2:]...)...)
+
+		res, changed, err := AutoOrientBytes(data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !changed {
+			t.Fatal("expected the image to change")
+		}
+
+		// JFIF is kept first, with its densities swapped
+		want := []byte("\xff\xe0\x00\x10JFIF\x00\x01\x02\x01\x00\x90\x00\x48\x00\x00")
+		if !bytes.Equal(res[2:2+len(want)], want) {
+			t.Errorf("expected: %q, got: %q", want, res[2:2+len(want)])
+		}
+		if !bytes.Contains(res, []byte("go-image")) {
+			t.Error("EXIF data was lost")
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(res)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func Test_DecodeJPEGOriented(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 24))
+	fillRandom(img.Pix, testSeed(t))
+
+	for or := TopLeft; or <= LeftBottom; or++ {
+		data := exifJPEG(t, img, or, binary.LittleEndian)
+
+		dec, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Image(dec, or.Op())
+
+		got, err := DecodeJPEGOriented(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got.(*image.YCbCr); !ok {
+			t.Errorf("%d: expected *image.YCbCr, got %T", or, got)
+		}
+
+		bounds := want.Bounds()
+		if bounds != got.Bounds() {
+			t.Fatalf("%d: expected: %v, got: %v", or, bounds, got.Bounds())
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if want.At(x, y) != got.At(x, y) {
+					t.Fatalf("%d: colors don't match at %2dx%d", or, x, y)
+				}
+			}
+		}
+	}
+}
+
+func Test_jpegSegmentsInvalid(t *testing.T) {
+	// an APP1 segment with a length of 0
+	data := []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x00, 0xff, 0xd9}
+
+	if or, _, _ := jpegOrientation(data); or != TopLeft {
+		t.Errorf("expected TopLeft, got %d", or)
+	}
+	if seg := jpegAppSegments(data); len(seg) != 0 {
+		t.Errorf("expected no segments, got %x", seg)
+	}
+	if res, changed, err := AutoOrientBytes(data, nil); err != nil || changed || !bytes.Equal(res, data) {
+		t.Errorf("expected the data unchanged, got %x, %v, %v", res, changed, err)
+	}
+	if _, err := DecodeJPEGOriented(bytes.NewReader(data)); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -4,8 +4,10 @@
 //
 // A fast path is used for all of the in-memory image types defined in that package.
 // An image of the same type is returned (chroma subsampling may change).
+// Other paletted images are returned as a Paletted image.
 //
 // A lazy, slow path, is used for other image types.
+// If the source image implements image.RGBA64Image, so does the result, without boxing colors.
 //
 // Example:
 //    exf := rotateflip.Orientation(exifOrientation)
@@ -13,8 +15,11 @@
 package rotateflip
 
 import (
+	"errors"
 	"image"
 	"image/color"
+	"image/draw"
+	"strconv"
 
 	"github.com/ncruces/go-image/imageutil"
 )
@@ -46,6 +51,31 @@ const (
 	Rotate270FlipXY = Rotate90
 )
 
+// String returns the name of the Operation.
+// Aliases (e.g. FlipXY, Rotate90FlipX) are named after the primary constant of the same value
+// (Rotate180, Transpose): None, Rotate90, Rotate180, Rotate270, FlipX, Transpose, FlipY or Transverse.
+func (op Operation) String() string {
+	switch op {
+	case None:
+		return "None"
+	case Rotate90:
+		return "Rotate90"
+	case Rotate180:
+		return "Rotate180"
+	case Rotate270:
+		return "Rotate270"
+	case FlipX:
+		return "FlipX"
+	case Transpose:
+		return "Transpose"
+	case FlipY:
+		return "FlipY"
+	case Transverse:
+		return "Transverse"
+	}
+	return "Operation(" + strconv.Itoa(int(op)) + ")"
+}
+
 // Orientation is an image orientation as specified by EXIF 2.2 and TIFF 6.0.
 type Orientation int
 
@@ -60,6 +90,30 @@ const (
 	LeftBottom
 )
 
+// String returns the name of the Orientation (TopLeft, TopRight, etc.).
+// Out of range values are formatted as Orientation(n).
+func (or Orientation) String() string {
+	switch or {
+	case TopLeft:
+		return "TopLeft"
+	case TopRight:
+		return "TopRight"
+	case BottomRight:
+		return "BottomRight"
+	case BottomLeft:
+		return "BottomLeft"
+	case LeftTop:
+		return "LeftTop"
+	case RightTop:
+		return "RightTop"
+	case RightBottom:
+		return "RightBottom"
+	case LeftBottom:
+		return "LeftBottom"
+	}
+	return "Orientation(" + strconv.Itoa(int(or)) + ")"
+}
+
 // Op gets the Operation that restores an image with this Orientation to TopLeft Orientation.
 func (or Orientation) Op() Operation {
 	switch or {
@@ -82,6 +136,107 @@ func (or Orientation) Op() Operation {
 	}
 }
 
+// ApplyOp gets the Operation that transforms a TopLeft image into an image with this Orientation.
+//
+// This is the inverse of Op: use Op to display an image that has this Orientation,
+// use ApplyOp to produce an image that has this Orientation.
+func (or Orientation) ApplyOp() Operation {
+	return or.Op().Inverse()
+}
+
+// Combined gets the Orientation of an image with this Orientation,
+// after another Orientation is applied on top of it.
+// Restoring it to TopLeft is the same as restoring this Orientation, then next.
+func (or Orientation) Combined(next Orientation) Orientation {
+	return orientation(or.Op().Then(next.Op()))
+}
+
+// OperationFor gets the Operation that rotates an image clockwise by degrees,
+// then flips it horizontally (mirrors x) if flipX, and vertically (mirrors y) if flipY.
+//
+// The rotation is applied first: OperationFor(90, true, false) is Rotate90FlipX (Transpose).
+// Degrees must be a multiple of 90 (negative values rotate counterclockwise),
+// other values cause a panic.
+func OperationFor(degrees int, flipX, flipY bool) Operation {
+	if degrees%90 != 0 {
+		panic("Invalid angle")
+	}
+
+	op := Operation((degrees/90%4 + 4) % 4)
+	if flipX {
+		op = op.Then(FlipX)
+	}
+	if flipY {
+		op = op.Then(FlipY)
+	}
+	return op
+}
+
+// Inverse gets the Operation that undoes this Operation.
+func (op Operation) Inverse() Operation {
+	op &= 7 // sanitize
+
+	switch op {
+	case Rotate90, Rotate270:
+		return op ^ 2
+	}
+	return op
+}
+
+// Then gets the Operation equivalent to applying this Operation, followed by next.
+func (op Operation) Then(next Operation) Operation {
+	op &= 7 // sanitize
+	next &= 7
+
+	rotate := (op ^ next) & 1
+	flip_x := op.FlipsX()
+	flip_y := op.FlipsY()
+	if next.Rotates() {
+		flip_x, flip_y = flip_y, flip_x
+	}
+	flip_x = flip_x != next.FlipsX()
+	flip_y = flip_y != next.FlipsY()
+
+	res := rotate
+	if flip_y {
+		res |= 2
+	}
+	if res.Parity() != flip_x {
+		res |= 4
+	}
+	return res
+}
+
+func orientation(op Operation) Orientation {
+	switch op & 7 {
+	default:
+		return TopLeft
+	case FlipX:
+		return TopRight
+	case FlipXY:
+		return BottomRight
+	case FlipY:
+		return BottomLeft
+	case Transpose:
+		return LeftTop
+	case Rotate90:
+		return RightTop
+	case Transverse:
+		return RightBottom
+	case Rotate270:
+		return LeftBottom
+	}
+}
+
+// ProfiledImage is an image with an embedded ICC color profile.
+//
+// Rotating and flipping doesn't change colors:
+// Image returns a ProfiledImage with the same profile, wrapping the transformed image.
+type ProfiledImage struct {
+	image.Image
+	ICC []byte
+}
+
 // Image applies an Operation to an image.
 func Image(src image.Image, op Operation) image.Image {
 	op &= 7 // sanitize
@@ -90,6 +245,14 @@ func Image(src image.Image, op Operation) image.Image {
 		return src // nop
 	}
 
+	// keep the profile
+	switch src := src.(type) {
+	case ProfiledImage:
+		return ProfiledImage{Image(src.Image, op), src.ICC}
+	case *ProfiledImage:
+		return ProfiledImage{Image(src.Image, op), src.ICC}
+	}
+
 	bounds := rotateBounds(src.Bounds(), op)
 
 	// fast path, eager
@@ -174,12 +337,220 @@ func Image(src image.Image, op Operation) image.Image {
 		rotateFlip(dst.Cb, dst.CStride, dstCBounds.Dx(), dstCBounds.Dy(), src.Cb, src.CStride, srcCBounds.Dx(), srcCBounds.Dy(), op, 1)
 		rotateFlip(dst.Cr, dst.CStride, dstCBounds.Dx(), dstCBounds.Dy(), src.Cr, src.CStride, srcCBounds.Dx(), srcCBounds.Dy(), op, 1)
 		return dst
+
+	case image.PalettedImage:
+		palette, ok := src.ColorModel().(color.Palette)
+		if !ok {
+			break
+		}
+
+		dst := image.NewPaletted(bounds, palette)
+		var i int
+		for y := 0; y < bounds.Max.Y; y++ {
+			for x := 0; x < bounds.Max.X; x++ {
+				dst.Pix[i] = src.ColorIndexAt(op.SourceCoord(x, y, src.Bounds()))
+				i++
+			}
+		}
+		return dst
 	}
 
 	// slow path, lazy
+	if _, ok := src.(image.RGBA64Image); ok {
+		return &rotateFlipRGBA64Image{rotateFlipImage{src, op}}
+	}
 	return &rotateFlipImage{src, op}
 }
 
+// ImageEager applies an Operation to an image.
+// Unlike Image, the slow path is eager: the result is drawn once into a new mutable image,
+// of the type that best matches the source's color model (*image.RGBA if none does).
+func ImageEager(src image.Image, op Operation) draw.Image {
+	img := Image(src, op)
+	if dst, ok := img.(draw.Image); ok && op&7 != 0 {
+		return dst
+	}
+
+	bounds := img.Bounds()
+	dst := newImageForModel(img.ColorModel(), bounds)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Src)
+	return dst
+}
+
+func newImageForModel(model color.Model, bounds image.Rectangle) draw.Image {
+	switch model {
+	case color.AlphaModel:
+		return image.NewAlpha(bounds)
+	case color.Alpha16Model:
+		return image.NewAlpha16(bounds)
+	case color.CMYKModel:
+		return image.NewCMYK(bounds)
+	case color.GrayModel:
+		return image.NewGray(bounds)
+	case color.Gray16Model:
+		return image.NewGray16(bounds)
+	case color.NRGBAModel:
+		return image.NewNRGBA(bounds)
+	case color.NRGBA64Model:
+		return image.NewNRGBA64(bounds)
+	case color.RGBA64Model:
+		return image.NewRGBA64(bounds)
+	}
+	if palette, ok := model.(color.Palette); ok {
+		return image.NewPaletted(bounds, palette)
+	}
+	return image.NewRGBA(bounds)
+}
+
+// ImageAligned applies an Operation to an image, returning an RGBA image
+// with a Stride that is a multiple of align bytes (e.g. for GPU upload).
+// Padding bytes at the end of each row are left zero.
+func ImageAligned(src image.Image, op Operation, align int) *image.RGBA {
+	op &= 7 // sanitize
+
+	if align < 1 {
+		align = 1
+	}
+
+	bounds := rotateBounds(src.Bounds(), op)
+	stride := (4*bounds.Dx() + align - 1) / align * align
+	dst := &image.RGBA{
+		Pix:    make([]uint8, stride*bounds.Dy()),
+		Stride: stride,
+		Rect:   bounds,
+	}
+
+	if src, ok := src.(*image.RGBA); ok {
+		rotateFlip(dst.Pix, dst.Stride, dst.Bounds().Dx(), dst.Bounds().Dy(), src.Pix, src.Stride, src.Bounds().Dx(), src.Bounds().Dy(), op, 4)
+		return dst
+	}
+
+	img := Image(src, op)
+	draw.Draw(dst, bounds, img, img.Bounds().Min, draw.Src)
+	return dst
+}
+
+// RotateRegion applies an Operation to the rectangle r of an image, in place,
+// leaving the rest of the image untouched.
+//
+// Operations that swap axes (rotations by 90° and 270°, transposes)
+// are only valid for square regions: for other regions, an error is returned.
+// An error is also returned if r is not inside the image.
+func RotateRegion(dst draw.Image, r image.Rectangle, op Operation) error {
+	op &= 7 // sanitize
+
+	if !r.In(dst.Bounds()) {
+		return errors.New("rotateflip: region is outside the image")
+	}
+	if op&1 != 0 && r.Dx() != r.Dy() {
+		return errors.New("rotateflip: operation changes the region's dimensions")
+	}
+	if op == 0 || r.Empty() {
+		return nil // nop
+	}
+
+	// copy the region, to use the fast path, then rotate it back into place
+	tmp := newImageForModel(dst.ColorModel(), r)
+	draw.Draw(tmp, r, dst, r.Min, draw.Src)
+	img := Image(tmp, op)
+	draw.Draw(dst, r, img, img.Bounds().Min, draw.Src)
+	return nil
+}
+
+// ImageRows applies an Operation to an image, producing the rows of the result in order,
+// and calling fn with each (y is relative to the top of the result).
+// It stops, and returns the error, if fn returns an error.
+//
+// For images with a single plane of pixels (*image.RGBA, *image.Gray, *image.Paletted, etc.),
+// rows have the pixel format of that plane, as in the Pix of the image returned by Image.
+// For other images, rows have the pixel format of the image type that best matches
+// the source's color model (*image.RGBA if none does), as in ImageEager.
+//
+// Only a row of the result is allocated, and reused across calls: fn must not retain it.
+// For operations that don't swap axes, each row is copied from a single source row;
+// operations that swap axes gather each row from a source column, which is slower
+// (as it reads memory with a large stride), but still avoids allocating the full result.
+func ImageRows(src image.Image, op Operation, fn func(y int, row []uint8) error) error {
+	op &= 7 // sanitize
+
+	srcBounds := src.Bounds()
+	bounds := rotateBounds(srcBounds, op)
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// fast path
+	if pix, stride, bpp, ok := pixels(src); ok {
+		local := image.Rect(0, 0, srcBounds.Dx(), srcBounds.Dy())
+		row := make([]uint8, bpp*w)
+		for y := 0; y < h; y++ {
+			if !op.Rotates() && !op.FlipsX() {
+				_, sy := op.SourceCoord(0, y, local)
+				copy(row, pix[sy*stride:])
+			} else {
+				for x := 0; x < w; x++ {
+					sx, sy := op.SourceCoord(x, y, local)
+					copy(row[bpp*x:bpp*x+bpp], pix[sy*stride+bpp*sx:])
+				}
+			}
+			if err := fn(y, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// slow path, a row at a time
+	rft := &rotateFlipImage{src, op}
+	dst := newImageForModel(src.ColorModel(), image.Rect(0, 0, w, 1))
+	row, _, _, _ := pixels(dst)
+	for y := 0; y < h; y++ {
+		draw.Draw(dst, dst.Bounds(), rft, bounds.Min.Add(image.Pt(0, y)), draw.Src)
+		if err := fn(y, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pixels returns the pixel plane of an image with a single plane,
+// and its stride and bytes per pixel.
+func pixels(img image.Image) (pix []uint8, stride, bpp int, ok bool) {
+	switch img := img.(type) {
+	case *image.Alpha:
+		return img.Pix, img.Stride, 1, true
+	case *image.Alpha16:
+		return img.Pix, img.Stride, 2, true
+	case *image.CMYK:
+		return img.Pix, img.Stride, 4, true
+	case *image.Gray:
+		return img.Pix, img.Stride, 1, true
+	case *image.Gray16:
+		return img.Pix, img.Stride, 2, true
+	case *image.NRGBA:
+		return img.Pix, img.Stride, 4, true
+	case *image.NRGBA64:
+		return img.Pix, img.Stride, 8, true
+	case *image.RGBA:
+		return img.Pix, img.Stride, 4, true
+	case *image.RGBA64:
+		return img.Pix, img.Stride, 8, true
+	case *image.Paletted:
+		return img.Pix, img.Stride, 1, true
+	}
+	return nil, 0, 0, false
+}
+
+// OrientToAspect rotates an image by 90° if its aspect doesn't match
+// the requested orientation (landscape, or portrait), and reports the Operation applied:
+// Rotate90 if the image was rotated, None otherwise.
+// Square images match either orientation.
+func OrientToAspect(src image.Image, landscape bool) (image.Image, Operation) {
+	size := src.Bounds().Size()
+	if landscape && size.X < size.Y || !landscape && size.X > size.Y {
+		return Image(src, Rotate90), Rotate90
+	}
+	return src, None
+}
+
 type rotateFlipImage struct {
 	src image.Image
 	op  Operation
@@ -194,31 +565,81 @@ func (rft *rotateFlipImage) Bounds() image.Rectangle {
 }
 
 func (rft *rotateFlipImage) At(x, y int) color.Color {
-	bounds := rft.src.Bounds()
-	switch rft.op {
+	return rft.src.At(rft.op.SourceCoord(x, y, rft.src.Bounds()))
+}
+
+type rotateFlipRGBA64Image struct {
+	rotateFlipImage
+}
+
+func (rft *rotateFlipRGBA64Image) RGBA64At(x, y int) color.RGBA64 {
+	return rft.src.(image.RGBA64Image).RGBA64At(rft.op.SourceCoord(x, y, rft.src.Bounds()))
+}
+
+// TransformPoint maps a pixel of a source image with bounds srcBounds
+// to the pixel it is copied to in the image produced by applying op,
+// relative to the top-left of that image (which is the origin, unless op is None).
+// It is the inverse of SourceCoord.
+//
+// To map a pixel of the produced image back to the source, use op.SourceCoord, or equivalently:
+//
+//	TransformPoint(op.Inverse(), p, image.Rectangle{Max: dstSize}).Add(srcBounds.Min)
+func TransformPoint(op Operation, p image.Point, srcBounds image.Rectangle) image.Point {
+	// the source is what undoing op produces from the result
+	p = p.Sub(srcBounds.Min)
+	x, y := op.Inverse().SourceCoord(p.X, p.Y, rotateBounds(srcBounds, op))
+	return image.Pt(x, y)
+}
+
+// SourceCoord maps a pixel of the image produced by applying this Operation
+// to a source image with the given bounds, back to the source pixel it is copied from.
+func (op Operation) SourceCoord(x, y int, bounds image.Rectangle) (int, int) {
+	switch op & 7 {
 	default:
-		return rft.src.At(bounds.Min.X+x, bounds.Min.Y+y)
+		return bounds.Min.X + x, bounds.Min.Y + y
 	case FlipX:
-		return rft.src.At(bounds.Max.X-x-1, bounds.Min.Y+y)
+		return bounds.Max.X - x - 1, bounds.Min.Y + y
 	case FlipXY:
-		return rft.src.At(bounds.Max.X-x-1, bounds.Max.Y-y-1)
+		return bounds.Max.X - x - 1, bounds.Max.Y - y - 1
 	case FlipY:
-		return rft.src.At(bounds.Min.X+x, bounds.Max.Y-y-1)
+		return bounds.Min.X + x, bounds.Max.Y - y - 1
 	case Transpose:
-		return rft.src.At(bounds.Min.X+y, bounds.Min.Y+x)
+		return bounds.Min.X + y, bounds.Min.Y + x
 	case Rotate90:
-		return rft.src.At(bounds.Min.X+y, bounds.Max.Y-x-1)
+		return bounds.Min.X + y, bounds.Max.Y - x - 1
 	case Transverse:
-		return rft.src.At(bounds.Max.X-y-1, bounds.Max.Y-x-1)
+		return bounds.Max.X - y - 1, bounds.Max.Y - x - 1
 	case Rotate270:
-		return rft.src.At(bounds.Max.X-y-1, bounds.Min.Y+x)
+		return bounds.Max.X - y - 1, bounds.Min.Y + x
 	}
 }
 
+// RemapTable returns the transform of this Operation as an index remap table,
+// for callers that copy pixels themselves (e.g. on the GPU).
+//
+// Pixel indexes are row-major, relative to the minimum point of their image:
+// the pixel at (x, y) of an image with width w has index y*w + x.
+// For each pixel of the image produced by applying op to a source image with the given bounds,
+// the table holds the index of the source pixel it is copied from.
+func RemapTable(srcBounds image.Rectangle, op Operation) []int {
+	bounds := rotateBounds(srcBounds, op&7)
+	w, h := bounds.Dx(), bounds.Dy()
+	sw := srcBounds.Dx()
+
+	res := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := op.SourceCoord(x, y, srcBounds)
+			res[y*w+x] = (sy-srcBounds.Min.Y)*sw + sx - srcBounds.Min.X
+		}
+	}
+	return res
+}
+
 func rotateFlip(dst []uint8, dst_stride, dst_width, dst_height int, src []uint8, src_stride, src_width, src_height int, op Operation, bpp int) {
-	rotate := op&1 != 0
-	flip_y := op&2 != 0
-	flip_x := parity(op)
+	rotate := op.Rotates()
+	flip_y := op.FlipsY()
+	flip_x := op.FlipsX()
 
 	var dst_row, src_row int
 
@@ -364,7 +785,33 @@ func subsampledBounds(bounds image.Rectangle, subsampleRatio image.YCbCrSubsampl
 	return bounds
 }
 
-func parity(op Operation) bool {
-	op = 0226 >> uint8(op)
+// Parity reports whether the value of this Operation has an odd number of bits set.
+// Given how Operation values are laid out, that's when it mirrors the x axis
+// of the image (see FlipsX).
+func (op Operation) Parity() bool {
+	op = 0226 >> uint8(op&7)
+	return op&1 != 0
+}
+
+// Rotates reports whether this Operation swaps the axes of an image.
+//
+// Every Operation decomposes into a transpose, if it rotates,
+// followed by flips of the resulting x and y axes, if it FlipsX and FlipsY.
+// So, a pixel at (x, y) of a w×h image that doesn't rotate is moved to:
+// (w-1-x, y) if it FlipsX, (x, h-1-y) if it FlipsY, (w-1-x, h-1-y) if both.
+// One that rotates is moved to (y, x), and then flipped in the same way.
+func (op Operation) Rotates() bool {
 	return op&1 != 0
 }
+
+// FlipsX reports whether this Operation mirrors the x axis of an image, after any transpose.
+// See Rotates for the decomposition.
+func (op Operation) FlipsX() bool {
+	return op.Parity()
+}
+
+// FlipsY reports whether this Operation mirrors the y axis of an image, after any transpose.
+// See Rotates for the decomposition.
+func (op Operation) FlipsY() bool {
+	return op&2 != 0
+}
@@ -1,14 +1,22 @@
 package rotateflip
 
 import (
+	"flag"
+	"fmt"
 	"image"
 	"image/color"
 	"image/color/palette"
+	"image/draw"
+	"io"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 func Test_Image(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
 	var subsample string
 	rect := image.Rect(0, 0, 16, 16)
 
@@ -49,52 +57,52 @@ func Test_Image(t *testing.T) {
 
 	{
 		img := image.NewAlpha(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewAlpha16(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewCMYK(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewGray(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewGray16(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewNRGBA(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewNRGBA64(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewRGBA(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewRGBA64(rect)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 	{
 		img := image.NewPaletted(rect, palette.Plan9)
-		random(img.Pix)
+		fillRandom(img.Pix, next())
 		testImg(img)
 	}
 
@@ -102,26 +110,38 @@ func Test_Image(t *testing.T) {
 		subsample = "(" + sr.String() + ")"
 		{
 			img := image.NewYCbCr(rect, sr)
-			random(img.Y)
-			random(img.Cb)
-			random(img.Cr)
+			fillRandom(img.Y, next())
+			fillRandom(img.Cb, next())
+			fillRandom(img.Cr, next())
 			testImg(img)
 		}
 		{
 			img := image.NewNYCbCrA(rect, sr)
-			random(img.Y)
-			random(img.Cb)
-			random(img.Cr)
-			random(img.A)
+			fillRandom(img.Y, next())
+			fillRandom(img.Cb, next())
+			fillRandom(img.Cr, next())
+			fillRandom(img.A, next())
 			testImg(img)
 		}
 	}
 }
 
-func random(pix []uint8) {
-	for i := range pix {
-		pix[i] = uint8(rand.Int63())
+var seedFlag = flag.Int64("seed", 0, "seed for random test images (0 for a time based seed)")
+
+// testSeed returns the seed for a test's random images, and logs it,
+// so failures can be reproduced with -seed.
+func testSeed(t *testing.T) int64 {
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
+	t.Logf("seed: %d", seed)
+	return seed
+}
+
+// fillRandom fills pix deterministically from seed.
+func fillRandom(pix []uint8, seed int64) {
+	rand.New(rand.NewSource(seed)).Read(pix)
 }
 
 type wrapper struct {
@@ -140,7 +160,768 @@ func (w *wrapper) At(x, y int) color.Color {
 	return w.i.At(x, y)
 }
 
+func Test_fillRandom(t *testing.T) {
+	a := make([]uint8, 64)
+	b := make([]uint8, 64)
+	fillRandom(a, 42)
+	fillRandom(b, 42)
+	if string(a) != string(b) {
+		t.Error("same seed, different pixels")
+	}
+	fillRandom(b, 43)
+	if string(a) == string(b) {
+		t.Error("different seeds, same pixels")
+	}
+}
+
 type imageWithSubImage interface {
 	image.Image
 	SubImage(image.Rectangle) image.Image
 }
+
+func Test_ImageEager(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	rect := image.Rect(0, 0, 16, 16)
+
+	testImg := func(img image.Image, typ string) {
+		for op := None; op <= Transverse; op++ {
+			rf1 := Image(&wrapper{img}, op)
+			rf2 := ImageEager(&wrapper{img}, op)
+
+			if res := fmt.Sprintf("%T", rf2); res != typ {
+				t.Errorf("%T/%d: expected: %s, got: %s", img, op, typ, res)
+			}
+
+			bounds := rf1.Bounds()
+			if bounds != rf2.Bounds() {
+				t.Errorf("%T/%d: bounds don't match", img, op)
+			}
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					if rf2.ColorModel().Convert(rf1.At(x, y)) != rf2.At(x, y) {
+						t.Errorf("%T/%d: colors don't match at %2dx%d", img, op, x, y)
+						return
+					}
+				}
+			}
+		}
+	}
+
+	{
+		img := image.NewRGBA(rect)
+		fillRandom(img.Pix, next())
+		testImg(img, "*image.RGBA")
+	}
+	{
+		img := image.NewNRGBA64(rect)
+		fillRandom(img.Pix, next())
+		testImg(img, "*image.NRGBA64")
+	}
+	{
+		img := image.NewGray(rect)
+		fillRandom(img.Pix, next())
+		testImg(img, "*image.Gray")
+	}
+	{
+		img := image.NewPaletted(rect, palette.Plan9)
+		fillRandom(img.Pix, next())
+		testImg(img, "*image.Paletted")
+	}
+	{
+		img := image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+		fillRandom(img.Y, next())
+		fillRandom(img.Cb, next())
+		fillRandom(img.Cr, next())
+		testImg(img, "*image.RGBA")
+	}
+}
+
+func Test_Then(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 5, 3))
+	fillRandom(img.Pix, testSeed(t))
+
+	for a := None; a <= Transverse; a++ {
+		for b := None; b <= Transverse; b++ {
+			rf1 := Image(Image(img, a), b).(*image.Gray)
+			rf2 := Image(img, a.Then(b)).(*image.Gray)
+			if rf1.Rect != rf2.Rect || string(rf1.Pix) != string(rf2.Pix) {
+				t.Errorf("%d.Then(%d): got %d", a, b, a.Then(b))
+			}
+		}
+	}
+}
+
+func Test_OperationString(t *testing.T) {
+	tests := map[Operation]string{
+		None:          "None",
+		Rotate90:      "Rotate90",
+		FlipXY:        "Rotate180",
+		Rotate270:     "Rotate270",
+		FlipX:         "FlipX",
+		Rotate90FlipX: "Transpose",
+		FlipY:         "FlipY",
+		Transverse:    "Transverse",
+		8:             "Operation(8)",
+		-1:            "Operation(-1)",
+	}
+	for op, want := range tests {
+		if got := fmt.Sprintf("%v", op); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func Test_ProfiledImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 5, 3))
+	fillRandom(img.Pix, testSeed(t))
+	icc := []byte("icc profile")
+
+	for _, src := range []image.Image{
+		ProfiledImage{img, icc},
+		&ProfiledImage{img, icc},
+	} {
+		res, ok := Image(src, Rotate90).(ProfiledImage)
+		if !ok {
+			t.Fatalf("expected a ProfiledImage, got %T", res)
+		}
+		if string(res.ICC) != string(icc) {
+			t.Errorf("expected %q, got %q", icc, res.ICC)
+		}
+		exp := Image(img, Rotate90).(*image.Gray)
+		if got, ok := res.Image.(*image.Gray); !ok || got.Rect != exp.Rect || string(got.Pix) != string(exp.Pix) {
+			t.Error("pixels weren't rotated")
+		}
+	}
+}
+
+func Test_OrientationString(t *testing.T) {
+	names := []string{"TopLeft", "TopRight", "BottomRight", "BottomLeft", "LeftTop", "RightTop", "RightBottom", "LeftBottom"}
+	for i, want := range names {
+		if got := fmt.Sprint(TopLeft + Orientation(i)); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+	for or, want := range map[Orientation]string{0: "Orientation(0)", 9: "Orientation(9)"} {
+		if got := or.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+
+	if n := testing.AllocsPerRun(10, func() {
+		for or := TopLeft; or <= LeftBottom; or++ {
+			_ = or.String()
+		}
+	}); n != 0 {
+		t.Errorf("expected no allocations, got %v", n)
+	}
+}
+
+func Test_Inverse(t *testing.T) {
+	img := image.NewRGBA(image.Rect(2, 1, 7, 4))
+	fillRandom(img.Pix, testSeed(t))
+
+	for op := None; op <= Transverse; op++ {
+		inv := op.Inverse()
+		if res := op.Then(inv); res != None {
+			t.Errorf("%v.Then(%v): got %v", op, inv, res)
+		}
+		switch op {
+		case Rotate90:
+			if inv != Rotate270 {
+				t.Errorf("%v.Inverse(): got %v", op, inv)
+			}
+		case Rotate270:
+			if inv != Rotate90 {
+				t.Errorf("%v.Inverse(): got %v", op, inv)
+			}
+		default:
+			if inv != op {
+				t.Errorf("%v.Inverse(): got %v", op, inv)
+			}
+		}
+
+		res := Image(Image(img, op), inv).(*image.RGBA)
+		if res.Rect.Size() != img.Rect.Size() {
+			t.Fatalf("%v: unexpected size: %v", op, res.Rect)
+		}
+		for y := 0; y < img.Rect.Dy(); y++ {
+			r := res.Pix[y*res.Stride:][:4*img.Rect.Dx()]
+			s := img.Pix[y*img.Stride:][:4*img.Rect.Dx()]
+			if string(r) != string(s) {
+				t.Errorf("%v: round trip doesn't match the original", op)
+				break
+			}
+		}
+	}
+}
+
+func Test_OperationFor(t *testing.T) {
+	tests := []struct {
+		degrees      int
+		flipX, flipY bool
+		want         Operation
+	}{
+		{0, false, false, None},
+		{90, false, false, Rotate90},
+		{180, false, false, Rotate180},
+		{270, false, false, Rotate270},
+		{0, true, false, FlipX},
+		{0, false, true, FlipY},
+		{0, true, true, FlipXY},
+		{90, true, false, Rotate90FlipX},
+		{180, true, false, Rotate180FlipX},
+		{270, true, false, Rotate270FlipX},
+		{90, false, true, Rotate90FlipY},
+		{180, false, true, Rotate180FlipY},
+		{270, false, true, Rotate270FlipY},
+		{90, true, true, Rotate90FlipXY},
+		{180, true, true, Rotate180FlipXY},
+		{270, true, true, Rotate270FlipXY},
+		{360, false, false, None},
+		{-90, false, false, Rotate270},
+		{-270, true, false, Rotate90FlipX},
+		{450, false, true, Rotate90FlipY},
+	}
+	for _, tt := range tests {
+		if got := OperationFor(tt.degrees, tt.flipX, tt.flipY); got != tt.want {
+			t.Errorf("OperationFor(%d, %v, %v): expected %v, got %v", tt.degrees, tt.flipX, tt.flipY, tt.want, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	OperationFor(45, false, false)
+}
+
+func Test_Combined(t *testing.T) {
+	for a := TopLeft; a <= LeftBottom; a++ {
+		if res := a.Combined(TopLeft); res != a {
+			t.Errorf("%d.Combined(TopLeft): got %d", a, res)
+		}
+		if res := TopLeft.Combined(a); res != a {
+			t.Errorf("TopLeft.Combined(%d): got %d", a, res)
+		}
+		for b := TopLeft; b <= LeftBottom; b++ {
+			if res := a.Combined(b).Op(); res != a.Op().Then(b.Op()) {
+				t.Errorf("%d.Combined(%d): got %d", a, b, res)
+			}
+			for c := TopLeft; c <= LeftBottom; c++ {
+				if a.Combined(b).Combined(c) != a.Combined(b.Combined(c)) {
+					t.Errorf("%d.Combined(%d).Combined(%d): not associative", a, b, c)
+				}
+			}
+		}
+	}
+}
+
+func Test_ApplyOp(t *testing.T) {
+	for or := TopLeft; or <= LeftBottom; or++ {
+		if res := or.ApplyOp().Then(or.Op()); res != None {
+			t.Errorf("%d.ApplyOp().Then(%d.Op()): got %d", or, or, res)
+		}
+		if res := or.Op().Then(or.ApplyOp()); res != None {
+			t.Errorf("%d.Op().Then(%d.ApplyOp()): got %d", or, or, res)
+		}
+	}
+}
+
+func Test_PalettedImage(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 16, 16), palette.Plan9)
+	fillRandom(img.Pix, testSeed(t))
+
+	for op := Rotate90; op <= Transverse; op++ {
+		rf1 := Image(img, op)
+		rf2, ok := Image(&palettedWrapper{wrapper{img}}, op).(*image.Paletted)
+		if !ok {
+			t.Errorf("%d: not a paletted image", op)
+			continue
+		}
+
+		bounds := rf1.Bounds()
+		if bounds != rf2.Bounds() {
+			t.Errorf("%d: bounds don't match", op)
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if rf1.At(x, y) != rf2.At(x, y) {
+					t.Errorf("%d: colors don't match at %2dx%d", op, x, y)
+					return
+				}
+			}
+		}
+	}
+}
+
+type palettedWrapper struct {
+	wrapper
+}
+
+func (w *palettedWrapper) ColorIndexAt(x, y int) uint8 {
+	return w.i.(image.PalettedImage).ColorIndexAt(x, y)
+}
+
+func Test_SourceCoord(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	fillRandom(img.Pix, testSeed(t))
+
+	for _, src := range []*image.Gray{
+		img,
+		img.SubImage(image.Rect(1, 2, 16, 16)).(*image.Gray),
+		img.SubImage(image.Rect(3, 1, 14, 15)).(*image.Gray),
+	} {
+		for op := None; op <= Transverse; op++ {
+			dst := Image(src, op)
+			bounds := dst.Bounds().Sub(dst.Bounds().Min)
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					sx, sy := op.SourceCoord(x, y, src.Bounds())
+					if src.At(sx, sy) != dst.At(dst.Bounds().Min.X+x, dst.Bounds().Min.Y+y) {
+						t.Errorf("%v/%d: colors don't match at %2dx%d", src.Rect, op, x, y)
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_TransformPoint(t *testing.T) {
+	img := image.NewGray(image.Rect(-2, 3, 5, 8))
+	fillRandom(img.Pix, testSeed(t))
+
+	for op := None; op <= Transverse; op++ {
+		// None returns the source, with its bounds
+		dst := Image(img, op).(*image.Gray)
+		size := image.Rectangle{Max: dst.Rect.Size()}
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+				p := image.Pt(x, y)
+				d := TransformPoint(op, p, img.Rect)
+				if q := d.Add(dst.Rect.Min); !d.In(size) || dst.GrayAt(q.X, q.Y) != img.GrayAt(x, y) {
+					t.Fatalf("%v: %v mapped to %v", op, p, d)
+				}
+				if sx, sy := op.SourceCoord(d.X, d.Y, img.Rect); image.Pt(sx, sy) != p {
+					t.Fatalf("%v: %v doesn't map back from %v", op, p, d)
+				}
+				if s := TransformPoint(op.Inverse(), d, size).Add(img.Rect.Min); s != p {
+					t.Fatalf("%v: %v inverts to %v", op, p, s)
+				}
+			}
+		}
+	}
+}
+
+func Test_ImageAligned(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 15, 13))
+	fillRandom(rgba.Pix, next())
+	gray := image.NewGray(image.Rect(0, 0, 15, 13))
+	fillRandom(gray.Pix, next())
+
+	for _, img := range []imageWithSubImage{rgba, gray} {
+		for _, src := range []image.Image{img, img.SubImage(image.Rect(1, 2, 14, 13))} {
+			for _, align := range []int{1, 4, 64, 256} {
+				for op := None; op <= Transverse; op++ {
+					rf1 := Image(src, op)
+					rf2 := ImageAligned(src, op, align)
+
+					if rf2.Stride%align != 0 || rf2.Stride < 4*rf2.Rect.Dx() {
+						t.Errorf("%T/%d: stride %d not aligned to %d", src, op, rf2.Stride, align)
+					}
+					bounds := rf2.Bounds()
+					if bounds.Size() != rf1.Bounds().Size() {
+						t.Errorf("%T/%d: bounds don't match", src, op)
+					}
+					for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+						for x := bounds.Min.X; x < bounds.Max.X; x++ {
+							exp := color.RGBAModel.Convert(rf1.At(rf1.Bounds().Min.X+x, rf1.Bounds().Min.Y+y))
+							if exp != rf2.At(x, y) {
+								t.Errorf("%T/%d: colors don't match at %2dx%d", src, op, x, y)
+								return
+							}
+						}
+						for _, p := range rf2.Pix[y*rf2.Stride+4*bounds.Dx() : (y+1)*rf2.Stride] {
+							if p != 0 {
+								t.Errorf("%T/%d: padding not zero", src, op)
+								return
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_RotateRegion(t *testing.T) {
+	orig := image.NewRGBA(image.Rect(2, 3, 17, 16))
+	fillRandom(orig.Pix, testSeed(t))
+
+	for _, r := range []image.Rectangle{
+		image.Rect(4, 5, 10, 11), // square
+		image.Rect(4, 5, 13, 9),  // wide
+		image.Rect(2, 3, 17, 16), // everything
+	} {
+		for op := None; op <= Transverse; op++ {
+			dst := image.NewRGBA(orig.Rect)
+			copy(dst.Pix, orig.Pix)
+
+			err := RotateRegion(dst, r, op)
+			if op&1 != 0 && r.Dx() != r.Dy() {
+				if err == nil {
+					t.Errorf("%v/%d: expected an error", r, op)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%v/%d: %v", r, op, err)
+			}
+
+			for y := orig.Rect.Min.Y; y < orig.Rect.Max.Y; y++ {
+				for x := orig.Rect.Min.X; x < orig.Rect.Max.X; x++ {
+					exp := orig.RGBAAt(x, y)
+					if image.Pt(x, y).In(r) {
+						sx, sy := op.SourceCoord(x-r.Min.X, y-r.Min.Y, image.Rectangle{Max: r.Size()})
+						exp = orig.RGBAAt(r.Min.X+sx, r.Min.Y+sy)
+					}
+					if exp != dst.RGBAAt(x, y) {
+						t.Errorf("%v/%d: colors don't match at %2dx%d", r, op, x, y)
+						return
+					}
+				}
+			}
+		}
+	}
+
+	if err := RotateRegion(orig, image.Rect(0, 0, 4, 4), Rotate90); err == nil {
+		t.Error("expected an error for a region outside the image")
+	}
+}
+
+func Test_ImageRows(t *testing.T) {
+	seed := testSeed(t)
+	next := func() int64 { seed++; return seed }
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 15, 13))
+	fillRandom(rgba.Pix, next())
+	gray16 := image.NewGray16(image.Rect(0, 0, 15, 13))
+	fillRandom(gray16.Pix, next())
+
+	for _, src := range []image.Image{
+		rgba,
+		rgba.SubImage(image.Rect(2, 3, 12, 11)),
+		gray16.SubImage(image.Rect(1, 1, 14, 6)),
+	} {
+		for op := None; op <= Transverse; op++ {
+			var res []uint8
+			err := ImageRows(src, op, func(y int, row []uint8) error {
+				if y != len(res)/len(row) {
+					t.Fatalf("%T/%d: unexpected row %d", src, op, y)
+				}
+				res = append(res, row...)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exp, _, _, _ := pixels(ImageEager(src, op))
+			if op == None {
+				// Image returns the source as is
+				exp, _, _, _ = pixels(ImageEager(Image(src, FlipX), FlipX))
+			}
+			if string(res) != string(exp) {
+				t.Errorf("%T/%d: rows don't match", src, op)
+			}
+		}
+	}
+
+	// the slow path draws rows
+	ycc := image.NewYCbCr(image.Rect(0, 0, 14, 10), image.YCbCrSubsampleRatio420)
+	fillRandom(ycc.Y, next())
+	fillRandom(ycc.Cb, next())
+	fillRandom(ycc.Cr, next())
+	for op := None; op <= Transverse; op++ {
+		img := Image(ycc, op)
+		exp := image.NewRGBA(img.Bounds())
+		draw.Draw(exp, exp.Rect, img, img.Bounds().Min, draw.Src)
+
+		var res []uint8
+		ImageRows(ycc, op, func(y int, row []uint8) error {
+			res = append(res, row...)
+			return nil
+		})
+		if string(res) != string(exp.Pix) {
+			t.Errorf("%T/%d: rows don't match", ycc, op)
+		}
+	}
+
+	// errors stop the rows
+	var n int
+	err := ImageRows(rgba, Rotate90, func(y int, row []uint8) error {
+		if n++; y == 4 {
+			return io.ErrShortWrite
+		}
+		return nil
+	})
+	if err != io.ErrShortWrite || n != 5 {
+		t.Errorf("expected an error after 5 rows, got %v after %d", err, n)
+	}
+}
+
+func Test_OrientToAspect(t *testing.T) {
+	portrait := image.NewRGBA(image.Rect(0, 0, 10, 20))
+	fillRandom(portrait.Pix, testSeed(t))
+
+	img, op := OrientToAspect(portrait, true)
+	if op != Rotate90 {
+		t.Errorf("expected Rotate90, got %d", op)
+	}
+	if r := image.Rect(0, 0, 20, 10); img.Bounds() != r {
+		t.Fatalf("expected: %v, got: %v", r, img.Bounds())
+	}
+	if img.At(19, 0) != portrait.At(0, 0) {
+		t.Error("expected a clockwise rotation")
+	}
+
+	if img, op := OrientToAspect(portrait, false); op != None || img != image.Image(portrait) {
+		t.Errorf("expected the image unchanged, got %d", op)
+	}
+	square := image.NewGray(image.Rect(0, 0, 8, 8))
+	for _, landscape := range []bool{false, true} {
+		if img, op := OrientToAspect(square, landscape); op != None || img != image.Image(square) {
+			t.Errorf("expected the image unchanged, got %d", op)
+		}
+	}
+}
+
+func Test_RGBA64At(t *testing.T) {
+	img := image.NewNRGBA64(image.Rect(0, 0, 16, 16))
+	fillRandom(img.Pix, testSeed(t))
+
+	if _, ok := Image(&wrapper{img}, Rotate90).(image.RGBA64Image); ok {
+		t.Error("unexpected image.RGBA64Image")
+	}
+
+	for _, src := range []image.Image{
+		&rgba64Wrapper{wrapper{img}},
+		&rgba64Wrapper{wrapper{img.SubImage(image.Rect(1, 2, 15, 16))}},
+	} {
+		for op := Rotate90; op <= Transverse; op++ {
+			rf, ok := Image(src, op).(image.RGBA64Image)
+			if !ok {
+				t.Fatalf("%T/%d: not an image.RGBA64Image", src, op)
+			}
+
+			bounds := rf.Bounds()
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					exp := color.RGBA64Model.Convert(rf.At(x, y))
+					if exp != rf.RGBA64At(x, y) {
+						t.Errorf("%T/%d: colors don't match at %2dx%d", src, op, x, y)
+						return
+					}
+				}
+			}
+		}
+	}
+
+	src := &rgba64Wrapper{wrapper{img}}
+	rf := Image(src, Rotate90).(image.RGBA64Image)
+	if n := testing.AllocsPerRun(100, func() { rf.RGBA64At(1, 2) }); n != 0 {
+		t.Errorf("RGBA64At allocates: %v", n)
+	}
+}
+
+type rgba64Wrapper struct {
+	wrapper
+}
+
+func (w *rgba64Wrapper) RGBA64At(x, y int) color.RGBA64 {
+	return w.i.(image.RGBA64Image).RGBA64At(x, y)
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	for _, size := range []image.Point{{16, 16}, {16, 15}, {15, 16}, {15, 15}, {12, 12}, {10, 10}} {
+		for kind := range 8 {
+			f.Add([]byte("seed"), uint8(size.X), uint8(size.Y), uint8(kind), uint8(kind))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, w, h, kind, op uint8) {
+		rect := image.Rect(int(w>>5), int(h>>5), int(w&31)+int(w>>5), int(h&31)+int(h>>5))
+
+		fill := func(pix []uint8) {
+			for i := range pix {
+				if len(data) > 0 {
+					pix[i] = data[i%len(data)]
+				}
+			}
+		}
+
+		var img image.Image
+		switch kind % 9 {
+		case 0:
+			i := image.NewGray(rect)
+			fill(i.Pix)
+			img = i
+		case 1:
+			i := image.NewGray16(rect)
+			fill(i.Pix)
+			img = i
+		case 2:
+			i := image.NewNRGBA(rect)
+			fill(i.Pix)
+			img = i
+		case 3:
+			i := image.NewRGBA64(rect)
+			fill(i.Pix)
+			img = i
+		case 4:
+			i := image.NewPaletted(rect, palette.Plan9)
+			fill(i.Pix)
+			img = i
+		case 5, 6, 7:
+			sr := []image.YCbCrSubsampleRatio{
+				image.YCbCrSubsampleRatio444,
+				image.YCbCrSubsampleRatio420,
+				image.YCbCrSubsampleRatio422,
+			}[kind%9-5]
+			i := image.NewYCbCr(rect, sr)
+			fill(i.Y)
+			fill(i.Cb)
+			fill(i.Cr)
+			img = i
+		case 8:
+			i := image.NewCMYK(rect)
+			fill(i.Pix)
+			img = &wrapper{i}
+		}
+
+		o := Operation(op)
+		rf := Image(img, o)
+		res := Image(rf, o.Inverse())
+
+		bounds := img.Bounds()
+		size := bounds.Size()
+		if o&1 != 0 {
+			size.X, size.Y = size.Y, size.X
+		}
+		if rf.Bounds().Size() != size {
+			t.Fatalf("%T/%d: unexpected size: %v", img, o&7, rf.Bounds())
+		}
+		if res.Bounds().Size() != bounds.Size() {
+			t.Fatalf("%T/%d: unexpected size: %v", img, o&7, res.Bounds())
+		}
+
+		min := res.Bounds().Min
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				if img.At(bounds.Min.X+x, bounds.Min.Y+y) != res.At(min.X+x, min.Y+y) {
+					t.Fatalf("%T/%d: colors don't match at %2dx%d", img, o&7, x, y)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkImage(b *testing.B) {
+	rect := image.Rect(0, 0, 1920, 1080)
+
+	images := []struct {
+		name string
+		img  image.Image
+	}{
+		{"RGBA", image.NewRGBA(rect)},
+		{"NRGBA", image.NewNRGBA(rect)},
+		{"Gray", image.NewGray(rect)},
+		{"YCbCr444", image.NewYCbCr(rect, image.YCbCrSubsampleRatio444)},
+		{"YCbCr422", image.NewYCbCr(rect, image.YCbCrSubsampleRatio422)},
+		{"YCbCr420", image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)},
+	}
+
+	for _, tt := range images {
+		for op := None; op <= Transverse; op++ {
+			b.Run(fmt.Sprintf("%s/%d", tt.name, op), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					Image(tt.img, op)
+				}
+			})
+		}
+	}
+}
+
+func Test_RemapTable(t *testing.T) {
+	img := image.NewGray(image.Rect(3, 5, 10, 9))
+	fillRandom(img.Pix, testSeed(t))
+	src := img.SubImage(image.Rect(4, 5, 9, 8)).(*image.Gray)
+	sw := src.Rect.Dx()
+
+	for op := None; op <= Transverse; op++ {
+		exp := Image(src, op).(*image.Gray)
+		table := RemapTable(src.Rect, op)
+		if len(table) != exp.Rect.Dx()*exp.Rect.Dy() {
+			t.Fatalf("%d: unexpected table length: %d", op, len(table))
+		}
+
+		w := exp.Rect.Dx()
+		for i, j := range table {
+			x, y := i%w, i/w
+			res := src.GrayAt(src.Rect.Min.X+j%sw, src.Rect.Min.Y+j/sw)
+			if e := exp.GrayAt(exp.Rect.Min.X+x, exp.Rect.Min.Y+y); res != e {
+				t.Errorf("%T/%d: colors don't match at %2dx%d", src, op, x, y)
+				break
+			}
+		}
+	}
+}
+
+func Test_OperationGeometry(t *testing.T) {
+	const w, h = 5, 3
+	src := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i)
+	}
+
+	for op := None; op <= Transverse; op++ {
+		if op.Parity() != op.FlipsX() {
+			t.Errorf("%d: Parity and FlipsX disagree", op)
+		}
+		if op.Parity() != (op == Rotate90 || op == Rotate180 || op == FlipX || op == Transverse) {
+			t.Errorf("%d: unexpected parity", op)
+		}
+
+		dw, dh := w, h
+		if op.Rotates() {
+			dw, dh = h, w
+		}
+		dst := image.NewGray(image.Rect(0, 0, dw, dh))
+		rotateFlip(dst.Pix, dst.Stride, dw, dh, src.Pix, src.Stride, w, h, op, 1)
+
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				dx, dy := x, y
+				if op.Rotates() {
+					dx, dy = y, x
+				}
+				if op.FlipsX() {
+					dx = dw - 1 - dx
+				}
+				if op.FlipsY() {
+					dy = dh - 1 - dy
+				}
+				if src.GrayAt(x, y) != dst.GrayAt(dx, dy) {
+					t.Fatalf("%d: pixel at %dx%d not moved to %dx%d", op, x, y, dx, dy)
+				}
+			}
+		}
+	}
+}